@@ -0,0 +1,202 @@
+package lrucache
+
+import (
+	"container/list"
+	"errors"
+	"math"
+	"sync"
+)
+
+// arcEntry is stored in every ARC list; ghost entries (b1, b2) keep only the key
+type arcEntry[K comparable, V any] struct {
+	key   K
+	value V
+	where *list.List
+}
+
+// arc implements the Adaptive Replacement Cache of Megiddo and Modha. Resident
+// entries live in t1 (seen once recently) and t2 (seen at least twice), while
+// b1 and b2 remember the keys recently evicted from t1 and t2. Hits on those
+// ghost keys adapt p, the target size of t1, trading recency for frequency.
+type arc[K comparable, V any] struct {
+	capacity int
+	p        int
+
+	t1, t2 *list.List
+	b1, b2 *list.List
+	m      map[K]*list.Element
+
+	lock sync.Mutex
+
+	stats stats
+}
+
+func (a *arc[K, V]) Get(key K) (value V, ok bool) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	element, ok := a.m[key]
+	if !ok || a.isGhost(element) {
+		a.stats.misses.Add(1)
+		var zero V
+		return zero, false
+	}
+
+	a.stats.hits.Add(1)
+	a.moveToFront(element, a.t2)
+	return entryOf[K, V](element).value, true
+}
+
+func (a *arc[K, V]) Put(key K, value V) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if element, ok := a.m[key]; ok {
+		entry := entryOf[K, V](element)
+		switch entry.where {
+		case a.b1:
+			// recency would have saved this key, grow t1
+			a.p = min(a.capacity, a.p+max(a.b2.Len()/a.b1.Len(), 1))
+			a.replace(false)
+		case a.b2:
+			// frequency would have saved this key, shrink t1
+			a.p = max(0, a.p-max(a.b1.Len()/a.b2.Len(), 1))
+			a.replace(true)
+		}
+		entry.value = value
+		a.moveToFront(element, a.t2)
+		return
+	}
+
+	t1b1 := a.t1.Len() + a.b1.Len()
+	switch {
+	case t1b1 == a.capacity:
+		if a.t1.Len() < a.capacity {
+			a.removeElement(a.b1.Back())
+			a.replace(false)
+		} else {
+			// b1 is empty, drop the LRU entry of t1 without remembering it
+			a.stats.evictions.Add(1)
+			a.removeElement(a.t1.Back())
+		}
+	case t1b1+a.t2.Len()+a.b2.Len() >= a.capacity:
+		if t1b1+a.t2.Len()+a.b2.Len() == 2*a.capacity {
+			a.removeElement(a.b2.Back())
+		}
+		a.replace(false)
+	}
+
+	entry := &arcEntry[K, V]{key: key, value: value, where: a.t1}
+	a.m[key] = a.t1.PushFront(entry)
+}
+
+// replace evicts the LRU entry of t1 or t2 into its ghost list, picking t1
+// when it is above its target size p
+func (a *arc[K, V]) replace(inB2 bool) {
+	if a.t1.Len()+a.t2.Len() < a.capacity {
+		// deletes left room, nothing to evict
+		return
+	}
+
+	if a.t1.Len() > 0 && (a.t1.Len() > a.p || (inB2 && a.t1.Len() == a.p) || a.t2.Len() == 0) {
+		a.demote(a.t1.Back(), a.b1)
+	} else {
+		a.demote(a.t2.Back(), a.b2)
+	}
+}
+
+// demote turns a resident entry into a ghost entry
+func (a *arc[K, V]) demote(element *list.Element, ghosts *list.List) {
+	a.stats.evictions.Add(1)
+	entry := entryOf[K, V](element)
+	var zero V
+	entry.value = zero
+	a.moveToFront(element, ghosts)
+}
+
+func (a *arc[K, V]) moveToFront(element *list.Element, to *list.List) {
+	entry := entryOf[K, V](element)
+	if entry.where == to {
+		to.MoveToFront(element)
+		return
+	}
+	entry.where.Remove(element)
+	entry.where = to
+	a.m[entry.key] = to.PushFront(entry)
+}
+
+func (a *arc[K, V]) removeElement(element *list.Element) {
+	entry := entryOf[K, V](element)
+	delete(a.m, entry.key)
+	entry.where.Remove(element)
+}
+
+func (a *arc[K, V]) isGhost(element *list.Element) bool {
+	where := entryOf[K, V](element).where
+	return where == a.b1 || where == a.b2
+}
+
+func entryOf[K comparable, V any](element *list.Element) *arcEntry[K, V] {
+	entry, ok := element.Value.(*arcEntry[K, V])
+	if !ok {
+		panic("list value is not of arc entry type")
+	}
+	return entry
+}
+
+func (a *arc[K, V]) Len() int {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.t1.Len() + a.t2.Len()
+}
+
+func (a *arc[K, V]) Delete(key K) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	element, ok := a.m[key]
+	if !ok || a.isGhost(element) {
+		return
+	}
+	a.removeElement(element)
+}
+
+func (a *arc[K, V]) Stats() (hits uint64, misses uint64, evictions uint64) {
+	return a.stats.hits.Load(), a.stats.misses.Load(), a.stats.evictions.Load()
+}
+
+func (a *arc[K, V]) Clear() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.stats = stats{}
+	a.p = 0
+	clear(a.m)
+	a.t1.Init()
+	a.t2.Init()
+	a.b1.Init()
+	a.b2.Init()
+}
+
+// NewARC creates an Adaptive Replacement Cache, which balances recency and
+// frequency and so resists one-off scans that would flush an LRU cache. It is
+// a separate type rather than a policy of New because the options and most
+// methods of the LRU cache (tags, loaders, Peek, Hottest and the like) are
+// built on its single recency list, which ARC replaces with four. It only has
+// Get, Put, Delete, Len, Clear and Stats, and it satisfies Cache.
+func NewARC[K comparable, V any](capacity uint) (*arc[K, V], error) {
+	if capacity == 0 {
+		return nil, errors.New("capacity should be greater than 0")
+	}
+	// the map holds resident and ghost keys, up to twice the capacity, so the
+	// capacity is bounded to keep that count within an int
+	size := int(min(capacity, math.MaxInt/2))
+	return &arc[K, V]{
+		capacity: size,
+		t1:       list.New(),
+		t2:       list.New(),
+		b1:       list.New(),
+		b2:       list.New(),
+		m:        make(map[K]*list.Element, 2*size),
+	}, nil
+}
@@ -0,0 +1,154 @@
+package lrucache
+
+import (
+	"container/list"
+	"slices"
+	"testing"
+)
+
+func arcKeys(l *list.List) []int {
+	keys := []int{}
+	for element := l.Front(); element != nil; element = element.Next() {
+		keys = append(keys, entryOf[int, int](element).key)
+	}
+	return keys
+}
+
+// the ARC cache has a smaller surface than the LRU cache but must stay a Cache
+var _ Cache[int, int] = (*arc[int, int])(nil)
+
+func TestARCZeroCapacity(t *testing.T) {
+	t.Parallel()
+	_, err := NewARC[int, string](0)
+	if err == nil {
+		t.Error("NewARC should return error when capacity is 0")
+	}
+}
+
+// TestARCReferenceTrace replays a trace and compares every step with the
+// state produced by the ARC pseudo-code from the original paper.
+// Lists are listed MRU first.
+func TestARCReferenceTrace(t *testing.T) {
+	t.Parallel()
+	cache, _ := NewARC[int, int](3)
+
+	steps := []struct {
+		key            int
+		hit            bool
+		p              int
+		t1, t2, b1, b2 []int
+	}{
+		{key: 1, p: 0, t1: []int{1}, t2: []int{}, b1: []int{}, b2: []int{}},
+		{key: 2, p: 0, t1: []int{2, 1}, t2: []int{}, b1: []int{}, b2: []int{}},
+		{key: 3, p: 0, t1: []int{3, 2, 1}, t2: []int{}, b1: []int{}, b2: []int{}},
+		{key: 1, hit: true, p: 0, t1: []int{3, 2}, t2: []int{1}, b1: []int{}, b2: []int{}},
+		{key: 4, p: 0, t1: []int{4, 3}, t2: []int{1}, b1: []int{2}, b2: []int{}},
+		// b1 ghost hit, p grows towards recency
+		{key: 2, p: 1, t1: []int{4}, t2: []int{2, 1}, b1: []int{3}, b2: []int{}},
+		{key: 5, p: 1, t1: []int{5, 4}, t2: []int{2}, b1: []int{3}, b2: []int{1}},
+		{key: 6, p: 1, t1: []int{6, 5}, t2: []int{2}, b1: []int{4}, b2: []int{1}},
+		{key: 2, hit: true, p: 1, t1: []int{6, 5}, t2: []int{2}, b1: []int{4}, b2: []int{1}},
+		{key: 7, p: 1, t1: []int{7, 6}, t2: []int{2}, b1: []int{5}, b2: []int{1}},
+		// b2 ghost hit, p shrinks towards frequency
+		{key: 1, p: 0, t1: []int{7}, t2: []int{1, 2}, b1: []int{6, 5}, b2: []int{}},
+		{key: 8, p: 0, t1: []int{8}, t2: []int{1, 2}, b1: []int{7, 6}, b2: []int{}},
+		{key: 5, p: 0, t1: []int{5}, t2: []int{1, 2}, b1: []int{8, 7}, b2: []int{}},
+		{key: 1, hit: true, p: 0, t1: []int{5}, t2: []int{1, 2}, b1: []int{8, 7}, b2: []int{}},
+		{key: 9, p: 0, t1: []int{9}, t2: []int{1, 2}, b1: []int{5, 8}, b2: []int{}},
+		{key: 2, hit: true, p: 0, t1: []int{9}, t2: []int{2, 1}, b1: []int{5, 8}, b2: []int{}},
+	}
+
+	for i, step := range steps {
+		_, hit := cache.Get(step.key)
+		if !hit {
+			cache.Put(step.key, step.key)
+		}
+
+		if hit != step.hit {
+			t.Errorf("step %d: expected hit to be %t for key: %d, but got: %t", i, step.hit, step.key, hit)
+		}
+		if cache.p != step.p {
+			t.Errorf("step %d: expected p to be %d, but got: %d", i, step.p, cache.p)
+		}
+		for name, lists := range map[string][2][]int{
+			"t1": {step.t1, arcKeys(cache.t1)},
+			"t2": {step.t2, arcKeys(cache.t2)},
+			"b1": {step.b1, arcKeys(cache.b1)},
+			"b2": {step.b2, arcKeys(cache.b2)},
+		} {
+			if !slices.Equal(lists[0], lists[1]) {
+				t.Errorf("step %d: expected %s to be %v, but got: %v", i, name, lists[0], lists[1])
+			}
+		}
+	}
+
+	hits, misses, evictions := cache.Stats()
+	if hits != 4 {
+		t.Errorf("expected hits to be 4, but got: %d", hits)
+	}
+	if misses != 12 {
+		t.Errorf("expected misses to be 12, but got: %d", misses)
+	}
+	if evictions != 9 {
+		t.Errorf("expected evictions to be 9, but got: %d", evictions)
+	}
+}
+
+func TestARCScanResistance(t *testing.T) {
+	t.Parallel()
+	cache, _ := NewARC[int, int](4)
+
+	// make 0 and 1 frequently used
+	for range 3 {
+		for key := range 2 {
+			if _, ok := cache.Get(key); !ok {
+				cache.Put(key, key)
+			}
+		}
+	}
+	// a one-off scan over many keys must not flush the frequent ones
+	for key := 100; key < 200; key++ {
+		cache.Put(key, key)
+	}
+
+	for key := range 2 {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("frequently used key: %d should have survived the scan", key)
+		}
+	}
+	if cache.Len() != 4 {
+		t.Errorf("expected cache length to be 4, but got: %d", cache.Len())
+	}
+}
+
+func TestARCDeleteAndClear(t *testing.T) {
+	t.Parallel()
+	cache, _ := NewARC[int, int](2)
+	cache.Put(1, 1)
+	cache.Put(2, 2)
+	cache.Put(3, 3) // 1 becomes a ghost
+
+	cache.Delete(1) // ghosts are not resident, nothing to delete
+	cache.Delete(2)
+	if cache.Len() != 1 {
+		t.Errorf("expected cache length to be 1, but got: %d", cache.Len())
+	}
+
+	// re-using the freed slot must not evict
+	cache.Put(1, 10)
+	if val, ok := cache.Get(1); !ok || val != 10 {
+		t.Errorf("expected key: 1 to exists with value: 10, but got: %d, %t", val, ok)
+	}
+	if _, ok := cache.Get(3); !ok {
+		t.Error("expected key: 3 to exists, but do not exists")
+	}
+
+	cache.Clear()
+	if cache.Len() != 0 {
+		t.Errorf("expected cache length to be 0, but got: %d", cache.Len())
+	}
+	hits, misses, evictions := cache.Stats()
+	if hits != 0 || misses != 0 || evictions != 0 {
+		t.Errorf("expected stats to be reset, but got: %d, %d, %d", hits, misses, evictions)
+	}
+}
@@ -0,0 +1,45 @@
+package lrucache
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// statsAudit tallies lookups and puts for WithStatsAudit, independently of
+// the stats counters it is checked against
+type statsAudit struct {
+	gets atomic.Uint64
+	puts atomic.Uint64
+}
+
+// auditGets tallies n lookups when WithStatsAudit is set
+func (c *cache[K, V]) auditGets(n int) {
+	if c.audit != nil {
+		c.audit.gets.Add(uint64(n))
+	}
+}
+
+// AuditStats cross-checks the statistics against the operations tallied by
+// WithStatsAudit since the cache was created or last cleared: every lookup
+// must be counted as exactly one hit or miss, and there cannot be more
+// evictions than puts. It returns an error describing the first violation, or
+// nil, also when the option is not set. Lookups still in progress can make
+// the check fail, so it is meant for tests and debugging once concurrent
+// calls have returned.
+func (c *cache[K, V]) AuditStats() error {
+	if c.audit == nil {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	gets, puts := c.audit.gets.Load(), c.audit.puts.Load()
+	hits, misses, evictions := c.stats.hits.Load(), c.stats.misses.Load(), c.stats.evictions.Load()
+	if hits+misses != gets {
+		return fmt.Errorf("%d hits and %d misses counted for %d lookups", hits, misses, gets)
+	}
+	if evictions > puts {
+		return fmt.Errorf("%d evictions counted for %d puts", evictions, puts)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package lrucache
+
+import "testing"
+
+func TestAuditStats(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](2, WithStatsAudit[int, int]())
+	for key := range 4 {
+		cache.Put(key, key)
+	}
+	cache.Get(3)
+	cache.Get(0)
+	cache.GetMultiStats([]int{2, 3, 4})
+	cache.GetOrCompute(5, func() (int, error) { return 5, nil })
+	cache.GetOrCompute(5, func() (int, error) { return 5, nil })
+	if err := cache.AuditStats(); err != nil {
+		t.Errorf("expected the audit to pass, but got: %v", err)
+	}
+
+	cache.Clear()
+	cache.Get(1)
+	if err := cache.AuditStats(); err != nil {
+		t.Errorf("expected the audit to pass after Clear, but got: %v", err)
+	}
+}
+
+func TestAuditStatsDetectsMismatch(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](2, WithStatsAudit[int, int]())
+	cache.Put(1, 1)
+	cache.Get(1)
+
+	// a double-counted hit
+	cache.stats.hits.Add(1)
+	if err := cache.AuditStats(); err == nil {
+		t.Error("expected the audit to detect the extra hit")
+	}
+	cache.stats.hits.Add(^uint64(0))
+
+	cache.stats.evictions.Add(2)
+	if err := cache.AuditStats(); err == nil {
+		t.Error("expected the audit to detect more evictions than puts")
+	}
+
+	plain, _ := New[int, int](2)
+	plain.stats.hits.Add(1)
+	if err := plain.AuditStats(); err != nil {
+		t.Errorf("expected no audit without WithStatsAudit, but got: %v", err)
+	}
+}
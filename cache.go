@@ -1,92 +1,845 @@
 package lrucache
 
 import (
-	"container/list"
+	"context"
 	"errors"
+	"iter"
+	"maps"
+	"math"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
+// defaultPriorityWindow is how many least recently used entries are
+// compared by priority when choosing an eviction victim
+const defaultPriorityWindow = 8
+
+// maxEvictionVetoes bounds how many candidates in a row the WithEvictionVeto
+// callback can protect before the chosen victim is evicted anyway
+const maxEvictionVetoes = 8
+
+// ErrKeyNotFound is returned by operations that require an existing key.
+var ErrKeyNotFound = errors.New("key not found")
+
+// ErrFrozen is returned by PutChecked while the cache is frozen.
+var ErrFrozen = errors.New("cache is frozen")
+
+// ErrKeyTooLarge is returned by PutChecked for keys above the WithMaxKeySize
+// limit.
+var ErrKeyTooLarge = errors.New("key too large")
+
+// Cache is the core set of methods shared by the caches returned by New and
+// NewUnbounded, for code that works with any cache, such as CopyTo.
+type Cache[K comparable, V any] interface {
+	Get(key K) (V, bool)
+	Put(key K, value V)
+	Delete(key K)
+	Len() int
+}
+
 type stats struct {
 	hits      atomic.Uint64
 	misses    atomic.Uint64
 	evictions atomic.Uint64
+	ghostHits atomic.Uint64
+	// inserts of new keys and value updates of existing keys by put
+	insertions atomic.Uint64
+	updates    atomic.Uint64
+	// loader and compute functions actually run, excluding shared waits
+	computations atomic.Uint64
+	// computations that returned a nil and a non-nil error
+	loadSuccesses atomic.Uint64
+	loadFailures  atomic.Uint64
+	// largest number of entries reached, updated under the write lock
+	peakLen atomic.Uint64
+}
+
+// reset zeroes every counter. Loads update the counters without the cache
+// lock, so each one is stored atomically instead of assigning a zero stats.
+func (s *stats) reset() {
+	s.hits.Store(0)
+	s.misses.Store(0)
+	s.evictions.Store(0)
+	s.ghostHits.Store(0)
+	s.insertions.Store(0)
+	s.updates.Store(0)
+	s.computations.Store(0)
+	s.loadSuccesses.Store(0)
+	s.loadFailures.Store(0)
+	s.peakLen.Store(0)
+}
+
+// StatsSink receives every hit, miss and eviction as it happens, to forward
+// them to a metrics backend. Its methods are called while the cache lock is
+// held, so they must be cheap, safe for concurrent use and must not call back
+// into the cache.
+type StatsSink interface {
+	IncHits()
+	IncMisses()
+	IncEvictions()
+}
+
+// StatsSnapshot is a point-in-time copy of the cache statistics.
+type StatsSnapshot struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	// Insertions counts puts of new keys, Updates puts of existing keys
+	Insertions uint64
+	Updates    uint64
+	// LoadSuccesses and LoadFailures count the loader and compute functions
+	// of the GetOrCompute family that returned a nil and a non-nil error
+	LoadSuccesses uint64
+	LoadFailures  uint64
+	// Length and Capacity are read under the same lock as each other
+	Length   int
+	Capacity uint
 }
 
 type container[K comparable, V any] struct {
-	key   K
-	value V
+	key      K
+	value    V
+	version  uint64
+	priority int
+	// set by reads when WithReferenceBit is enabled, cleared by eviction
+	referenced atomic.Bool
+	// insertion sequence number and hit count, for WithEvictionComparator
+	inserted uint64
+	hits     atomic.Uint64
+
+	// links in the recency list
+	prev, next *container[K, V]
+}
+
+// EntryInfo describes a cached entry to an eviction comparator.
+type EntryInfo[K comparable, V any] struct {
+	Key      K
+	Value    V
+	Priority int
+	// Inserted orders the entries by when their key was inserted: the
+	// smaller, the older. Updating the value keeps it.
+	Inserted uint64
+	// Hits counts the Get hits on the entry since it was inserted.
+	Hits uint64
+}
+
+// Pair is a key-value pair for batch operations.
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
 }
 
 type cache[K comparable, V any] struct {
+	// capacity is 0 for unbounded caches
 	capacity uint
 
-	orderList *list.List
-	m         map[K]*list.Element
+	orderList *entryList[K, V]
+	m         map[K]*container[K, V]
 
 	lock sync.RWMutex
+	// set by Freeze; writers wait on unfrozen while it is true
+	frozen   bool
+	unfrozen *sync.Cond
 
 	stats stats
+	// last insertion sequence number handed out, for EntryInfo.Inserted
+	insertSeq uint64
+
+	initialMapSize uint
+	equal          func(a, b V) bool
+	skipEqual      func(a, b V) bool
+	evictionLess   func(a, b EntryInfo[K, V]) bool
+	evictionVeto   func(K, V) bool
+	// defaults remembered by WithCacheDefaults, nil without it
+	defaults       *expiring[K, V]
+	stableOrder    bool
+	priorityWindow int
+	evictBatch     uint
+	referenceBit   bool
+	maxKeySize     int
+	keySize        func(K) int
+	evictionLog    *evictionLog[K]
+	evictionRate   evictionRate
+	ghosts         *ghostList[K]
+	hitWindow      *hitWindow
+	sink           StatsSink
+	// recycles evicted entries, nil unless WithElementPool is set
+	pool *sync.Pool
+
+	// set by the first PutWithPriority, until then eviction ignores priorities
+	prioritized bool
+
+	onAccess func(key K, hit bool)
+
+	nearEvictionWindow int
+	onNearEviction     func(K)
+	// keys for onNearEviction, delivered once the write lock is released
+	pendingNearEviction []K
+
+	evictionEvery   uint64
+	onEvictionEvery func(StatsSnapshot)
+	// snapshots for onEvictionEvery, delivered once the write lock is released
+	pendingSnapshots []StatsSnapshot
+
+	sampleEvery int
+	onSample    func(EvictionEvent[K])
+	// sampled evictions for onSample, delivered once the write lock is released
+	pendingSamples []EvictionEvent[K]
+
+	onEvict func(key K, value V, reason EvictionReason)
+	// removed entries for onEvict, delivered once the write lock is released
+	pendingEvicted []evictedEntry[K, V]
+
+	// in-flight computations of GetOrCompute, keyed by the missing key
+	calls     map[K]*call[V]
+	callsLock sync.Mutex
+	// loader errors cached by WithErrorCaching, nil without it
+	failures *expiring[K, error]
+	// semaphore bounding concurrent loader calls, nil when unbounded
+	loadSlots chan struct{}
+	loader    func(K) (V, error)
+
+	now func() time.Time
+	// set by WithGhostTTL, applied to ghosts once all options have run
+	ghostTTL time.Duration
+
+	// reverse index of the tags set by PutWithTags, nil until first used
+	tags *tagIndex[K]
+
+	// started by WithStatsLogger and stopped by Close
+	statsLog *statsLogger
+	// tallies checked by AuditStats, nil without WithStatsAudit
+	audit *statsAudit
 }
 
 func (c *cache[K, V]) Get(key K) (value V, ok bool) {
+	value, ok, _ = c.get(key)
+	// called after the lock is released
+	if c.onAccess != nil {
+		c.onAccess(key, ok)
+	}
+	return value, ok
+}
+
+// GetPromoted is Get that also reports whether the hit moved the entry to the
+// front. Hits on the front entry, hits with WithReferenceBit and hits while
+// the cache is frozen leave the order unchanged and report false, which helps
+// to validate the promotion behaviour of the cache.
+func (c *cache[K, V]) GetPromoted(key K) (value V, ok, promoted bool) {
+	value, ok, promoted = c.get(key)
+	if c.onAccess != nil {
+		c.onAccess(key, ok)
+	}
+	return value, ok, promoted
+}
+
+func (c *cache[K, V]) get(key K) (value V, ok, promoted bool) {
+	c.auditGets(1)
+	// the read-locked fast path cannot tell the entry's distance to the tail
+	if c.onNearEviction == nil {
+		if value, ok, _ := c.getFast(key, false); ok {
+			return value, true, false
+		}
+	}
+
 	c.lock.Lock()
-	defer c.lock.Unlock()
+	defer c.unlock()
 
+	return c.getLocked(key)
+}
+
+// GetMultiStats looks up every key under a single write lock, like Get,
+// returning the values found along with how many of the keys hit and missed in
+// this call. Keys given more than once are counted every time.
+func (c *cache[K, V]) GetMultiStats(keys []K) (found map[K]V, hits, misses int) {
+	found = make(map[K]V, len(keys))
+	c.auditGets(len(keys))
+	c.lock.Lock()
+	for _, key := range keys {
+		if value, ok, _ := c.getLocked(key); ok {
+			found[key] = value
+			hits++
+		} else {
+			misses++
+		}
+	}
+	c.unlock()
+
+	if c.onAccess != nil {
+		for _, key := range keys {
+			_, ok := found[key]
+			c.onAccess(key, ok)
+		}
+	}
+	return found, hits, misses
+}
+
+// GetCtx is Get that gives up waiting for the lock once ctx is done,
+// returning ctx.Err(). This bounds how long a caller can be blocked behind
+// other operations under heavy contention.
+func (c *cache[K, V]) GetCtx(ctx context.Context, key K) (value V, ok bool, err error) {
+	// the read-locked front fast path is skipped as RLock cannot be abandoned
+	if err := c.lockCtx(ctx); err != nil {
+		var zero V
+		return zero, false, err
+	}
+	c.auditGets(1)
+	value, ok, _ = c.getLocked(key)
+	c.unlock()
+
+	if c.onAccess != nil {
+		c.onAccess(key, ok)
+	}
+	return value, ok, nil
+}
+
+// lockCtx acquires the write lock by polling TryLock with exponential backoff
+// until it succeeds or ctx is done
+func (c *cache[K, V]) lockCtx(ctx context.Context) error {
+	backoff := time.Microsecond
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if c.lock.TryLock() {
+			return nil
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, time.Millisecond)
+	}
+}
+
+// getLocked must be called with the write lock held. promoted reports whether
+// a hit moved the entry to the front.
+func (c *cache[K, V]) getLocked(key K) (value V, ok, promoted bool) {
 	element, ok := c.m[key]
 
 	if !ok {
+		c.recordLookup(false)
+		if c.ghosts != nil && c.ghosts.remove(key) {
+			c.stats.ghostHits.Add(1)
+		}
+		var zero V
+		return zero, false, false
+	}
+
+	c.recordLookup(true)
+	if c.evictionLess != nil {
+		element.hits.Add(1)
+	}
+	if c.onNearEviction != nil && c.nearEviction(element) {
+		c.pendingNearEviction = append(c.pendingNearEviction, key)
+	}
+
+	switch {
+	case c.referenceBit:
+		element.referenced.Store(true)
+	case !c.frozen && element != c.orderList.Front():
+		c.orderList.MoveToFront(element)
+		promoted = true
+	}
+
+	return element.value, true, promoted
+}
+
+// nearEviction reports whether element is among the nearEvictionWindow least
+// recently used entries
+func (c *cache[K, V]) nearEviction(element *container[K, V]) bool {
+	tail := c.orderList.Back()
+	for i := 0; i < c.nearEvictionWindow && tail != nil; i, tail = i+1, tail.Prev() {
+		if tail == element {
+			return true
+		}
+	}
+	return false
+}
+
+// lockWritable acquires the write lock for an operation that modifies the
+// cache, first waiting for the cache to be unfrozen
+func (c *cache[K, V]) lockWritable() {
+	c.lock.Lock()
+	for c.frozen {
+		c.unfrozen.Wait()
+	}
+}
+
+// Freeze makes the cache read-only until Unfreeze is called. Operations that
+// modify the cache block until then, PutChecked returns ErrFrozen, and Get
+// still serves hits but no longer promotes them, so the recency order stays
+// stable for a consistent snapshot.
+func (c *cache[K, V]) Freeze() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.frozen = true
+}
+
+// Unfreeze ends Freeze and wakes the operations waiting for it.
+func (c *cache[K, V]) Unfreeze() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.frozen = false
+	c.unfrozen.Broadcast()
+}
+
+// getFast serves the hits that need no promotion under the read lock: hits on
+// the most recently used entry, where promoting it would be a no-op, and with
+// WithReferenceBit every hit, which sets the entry's reference bit instead.
+// Other lookups report false and have to take the write lock; found tells
+// misses apart from hits that need promoting. With countMiss a miss is counted
+// right away, under the read lock like the hits.
+func (c *cache[K, V]) getFast(key K, countMiss bool) (value V, ok, found bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	element, found := c.m[key]
+	if !found && countMiss {
+		c.recordLookup(false)
+	}
+	if !found || (!c.referenceBit && element != c.orderList.Front()) {
+		var zero V
+		return zero, false, found
+	}
+
+	if c.referenceBit {
+		element.referenced.Store(true)
+	}
+	if c.evictionLess != nil {
+		element.hits.Add(1)
+	}
+	c.recordLookup(true)
+	return element.value, true, true
+}
+
+// recordLookup counts a hit or miss in the stats, the WithHitRateWindow window
+// and the WithStatsSink sink
+func (c *cache[K, V]) recordLookup(hit bool) {
+	if hit {
+		c.stats.hits.Add(1)
+	} else {
 		c.stats.misses.Add(1)
+	}
+	if c.hitWindow != nil {
+		c.hitWindow.record(hit)
+	}
+	if c.sink != nil {
+		if hit {
+			c.sink.IncHits()
+		} else {
+			c.sink.IncMisses()
+		}
+	}
+}
+
+// peek looks up key without promoting it or counting stats
+func (c *cache[K, V]) peek(key K) (value V, ok bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	element, ok := c.m[key]
+	if !ok {
 		var zero V
 		return zero, false
 	}
+	return element.value, true
+}
 
-	c.stats.hits.Add(1)
+func (c *cache[K, V]) Put(key K, value V) {
+	c.lockWritable()
+	defer c.unlock()
 
-	cvalue, ok := element.Value.(*container[K, V])
+	c.put(key, value, nil)
+}
 
+// PutChecked is Put that returns ErrKeyTooLarge instead of silently dropping
+// keys above the WithMaxKeySize limit, and ErrFrozen instead of waiting while
+// the cache is frozen.
+func (c *cache[K, V]) PutChecked(key K, value V) error {
+	if c.keyTooLarge(key) {
+		return ErrKeyTooLarge
+	}
+
+	c.lock.Lock()
+	defer c.unlock()
+
+	if c.frozen {
+		return ErrFrozen
+	}
+	c.put(key, value, nil)
+	return nil
+}
+
+// keyTooLarge reports whether key exceeds the WithMaxKeySize limit
+func (c *cache[K, V]) keyTooLarge(key K) bool {
+	return c.keySize != nil && c.keySize(key) > c.maxKeySize
+}
+
+// PutWithPriority stores the value like Put and sets the entry's priority.
+// On eviction the entry with the lowest priority among the priority window
+// of least recently used entries is evicted, so higher priorities survive
+// longer. Entries stored with Put have priority 0, and updating a value with
+// Put keeps the entry's priority.
+func (c *cache[K, V]) PutWithPriority(key K, value V, priority int) {
+	c.lockWritable()
+	defer c.unlock()
+
+	c.prioritized = true
+	c.put(key, value, nil)
+	element, ok := c.m[key]
 	if !ok {
-		panic("list value is not of container type")
+		// rejected by WithMaxKeySize
+		return
 	}
+	element.priority = priority
+}
 
-	c.orderList.MoveToFront(element)
+// SetValue replaces the value of an existing entry and promotes it, returning
+// ErrKeyNotFound instead of inserting when the key is absent.
+func (c *cache[K, V]) SetValue(key K, value V) error {
+	c.lockWritable()
+	defer c.lock.Unlock()
 
-	return cvalue.value, true
+	if _, ok := c.m[key]; !ok {
+		return ErrKeyNotFound
+	}
+	c.put(key, value, nil)
+	return nil
 }
 
-func (c *cache[K, V]) Put(key K, value V) {
-	c.lock.Lock()
+// Swap stores the value like Put and returns the previous one, like Swap of
+// sync.Map. loaded reports whether the key was cached; if not, old is the
+// zero value and the value is inserted, evicting if the cache is full.
+func (c *cache[K, V]) Swap(key K, value V) (old V, loaded bool) {
+	c.lockWritable()
+	defer c.unlock()
+
+	if element, ok := c.m[key]; ok {
+		old, loaded = element.value, true
+	}
+	c.put(key, value, nil)
+	return old, loaded
+}
+
+// PutAll inserts every item and returns the keys evicted to make room for
+// them. Map iteration order is unspecified, so which keys get evicted may vary
+// between calls, but without WithEvictBatch their count is always
+// max(0, existing + new - capacity).
+func (c *cache[K, V]) PutAll(items map[K]V) []K {
+	c.lockWritable()
+	defer c.unlock()
+
+	var evictedKeys []K
+	for key, value := range items {
+		c.put(key, value, &evictedKeys)
+	}
+	return evictedKeys
+}
+
+// PutPairs inserts the pairs in order under a single lock. When a key appears
+// more than once the last occurrence wins, both for the stored value and for
+// its recency.
+func (c *cache[K, V]) PutPairs(pairs []Pair[K, V]) {
+	c.lockWritable()
+	defer c.unlock()
+
+	for _, pair := range pairs {
+		c.put(pair.Key, pair.Value, nil)
+	}
+}
+
+// Version returns the version of the entry for key without promoting it.
+// Versions start at 1 on insertion and increase by one on every Put.
+func (c *cache[K, V]) Version(key K) (uint64, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	element, ok := c.m[key]
+	if !ok {
+		return 0, false
+	}
+	return element.version, true
+}
+
+// PutIfVersion stores value only if the entry's current version equals
+// expectedVersion, where an absent key has version 0. It reports whether the
+// value was stored, which is false despite a matching version when
+// WithSkipEqualPuts skips an equal value or WithMaxKeySize rejects the key.
+func (c *cache[K, V]) PutIfVersion(key K, value V, expectedVersion uint64) bool {
+	c.lockWritable()
+	defer c.unlock()
+
+	var version uint64
+	if element, ok := c.m[key]; ok {
+		version = element.version
+	}
+	if version != expectedVersion {
+		return false
+	}
+
+	return c.put(key, value, nil)
+}
+
+// Rename moves the entry of oldKey to newKey, keeping its value, version and
+// position in the recency order. It reports false without changes if oldKey
+// is absent, newKey is already cached or WithMaxKeySize rejects newKey.
+func (c *cache[K, V]) Rename(oldKey, newKey K) bool {
+	c.lockWritable()
 	defer c.lock.Unlock()
 
+	element, ok := c.m[oldKey]
+	if !ok {
+		return false
+	}
+	if _, ok := c.m[newKey]; ok || c.keyTooLarge(newKey) {
+		return false
+	}
+
+	element.key = newKey
+	delete(c.m, oldKey)
+	c.m[newKey] = element
+	if c.ghosts != nil {
+		c.ghosts.remove(newKey)
+	}
+	if c.tags != nil {
+		c.tags.rename(oldKey, newKey)
+	}
+	return true
+}
+
+// put must be called with the write lock held. Keys evicted to make room are
+// appended to evictedKeys unless it is nil. It reports whether the value was
+// stored, which WithSkipEqualPuts and WithMaxKeySize can prevent.
+func (c *cache[K, V]) put(key K, value V, evictedKeys *[]K) bool {
+	if c.audit != nil {
+		c.audit.puts.Add(1)
+	}
 	// check if key is already existing in cache
-	val, ok := c.m[key]
+	element, ok := c.m[key]
 	if ok {
-		cVal := val.Value.(*container[K, V])
-		cVal.value = value
-		c.orderList.MoveToFront(val)
-		return
+		if c.skipEqual != nil && c.skipEqual(element.value, value) {
+			return false
+		}
+		c.stats.updates.Add(1)
+		element.value = value
+		element.version++
+		if !c.stableOrder {
+			c.orderList.MoveToFront(element)
+		}
+		return true
+	}
+	if c.keyTooLarge(key) {
+		return false
+	}
+	if c.ghosts != nil {
+		c.ghosts.remove(key)
 	}
 	// key does not exist, first check capacity
-	if uint(len(c.m)) == c.capacity {
-		// evict last key
-		lastC := c.orderList.Back().Value
-		val, ok := lastC.(*container[K, V])
-		if !ok {
-			panic("element value not of container type")
+	if c.full() {
+		for range min(c.evictBatch, uint(len(c.m))) {
+			evictedKey := c.evict(ReasonCapacity)
+			if evictedKeys != nil {
+				*evictedKeys = append(*evictedKeys, evictedKey)
+			}
 		}
-		// first delete from map
-		// then delete from linked list
-		c.stats.evictions.Add(1)
-		delete(c.m, val.key)
-		c.orderList.Remove(c.orderList.Back())
 	}
 
-	newC := &container[K, V]{
-		key:   key,
-		value: value,
-	}
+	c.stats.insertions.Add(1)
+	newC := c.newContainer()
+	newC.key, newC.value, newC.version = key, value, 1
+	c.insertSeq++
+	newC.inserted = c.insertSeq
 
 	c.m[key] = c.orderList.PushFront(newC)
+	if n := uint64(len(c.m)); n > c.stats.peakLen.Load() {
+		c.stats.peakLen.Store(n)
+	}
+	return true
+}
+
+// newContainer returns a zeroed entry, recycled from the pool when
+// WithElementPool is set
+func (c *cache[K, V]) newContainer() *container[K, V] {
+	if c.pool != nil {
+		if val, ok := c.pool.Get().(*container[K, V]); ok {
+			return val
+		}
+	}
+	return &container[K, V]{}
+}
+
+// unlock releases the write lock, then runs the callbacks queued while it was
+// held so that they can safely call back into the cache
+func (c *cache[K, V]) unlock() {
+	snapshots, samples, evicted := c.pendingSnapshots, c.pendingSamples, c.pendingEvicted
+	c.pendingSnapshots, c.pendingSamples, c.pendingEvicted = nil, nil, nil
+	nearEviction := c.pendingNearEviction
+	c.pendingNearEviction = nil
+	c.lock.Unlock()
+
+	for _, key := range nearEviction {
+		c.onNearEviction(key)
+	}
+	for _, entry := range evicted {
+		c.onEvict(entry.key, entry.value, entry.reason)
+	}
+	for _, snapshot := range snapshots {
+		c.onEvictionEvery(snapshot)
+	}
+	for _, sample := range samples {
+		c.onSample(sample)
+	}
+}
+
+// full also reports true if the length ever exceeds capacity, so that
+// eviction still triggers instead of letting the cache grow further
+func (c *cache[K, V]) full() bool {
+	// len is never negative, so converting it to uint cannot wrap around
+	return c.capacity != 0 && uint(len(c.m)) >= c.capacity
+}
+
+// evict removes the least recently used entry, or the least entry per the
+// eviction comparator when one is configured
+func (c *cache[K, V]) evict(reason EvictionReason) K {
+	return c.evictElement(c.victim(), reason)
+}
+
+// evictElement removes element, recording it as evicted for reason
+func (c *cache[K, V]) evictElement(element *container[K, V], reason EvictionReason) K {
+	c.recordEviction(element.key, reason)
+	if c.onEvict != nil {
+		c.pendingEvicted = append(c.pendingEvicted, evictedEntry[K, V]{key: element.key, value: element.value, reason: reason})
+	}
+	if c.ghosts != nil {
+		c.ghosts.add(element.key)
+	}
+	key := element.key
+	c.remove(element)
+	if c.pool != nil {
+		// clear the entry so that the pool does not keep its key and value alive
+		*element = container[K, V]{}
+		c.pool.Put(element)
+	}
+	return key
+}
+
+// remove deletes an entry, first from the map then from the linked list
+func (c *cache[K, V]) remove(element *container[K, V]) {
+	delete(c.m, element.key)
+	c.orderList.Remove(element)
+	if c.tags != nil {
+		c.tags.remove(element.key)
+	}
+}
+
+func (c *cache[K, V]) recordEviction(key K, reason EvictionReason) {
+	evictions := c.stats.evictions.Add(1)
+	if c.sink != nil {
+		c.sink.IncEvictions()
+	}
+	if c.evictionEvery != 0 && evictions%c.evictionEvery == 0 {
+		c.pendingSnapshots = append(c.pendingSnapshots, StatsSnapshot{
+			Hits:          c.stats.hits.Load(),
+			Misses:        c.stats.misses.Load(),
+			Evictions:     evictions,
+			Insertions:    c.stats.insertions.Load(),
+			Updates:       c.stats.updates.Load(),
+			LoadSuccesses: c.stats.loadSuccesses.Load(),
+			LoadFailures:  c.stats.loadFailures.Load(),
+			Length:        len(c.m),
+			Capacity:      c.capacity,
+		})
+	}
+	if c.evictionLog == nil && c.sampleEvery == 0 {
+		return
+	}
+	event := EvictionEvent[K]{Key: key, Reason: reason, Time: c.now()}
+	if c.evictionLog != nil {
+		c.evictionLog.add(event)
+	}
+	if c.sampleEvery != 0 && evictions%uint64(c.sampleEvery) == 0 {
+		c.pendingSamples = append(c.pendingSamples, event)
+	}
+}
+
+// RecentEvictions returns the evictions retained by WithEvictionLog, oldest
+// first. It returns nil when the log is not enabled.
+func (c *cache[K, V]) RecentEvictions() []EvictionEvent[K] {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.evictionLog == nil {
+		return nil
+	}
+	return c.evictionLog.snapshot()
+}
+
+func (c *cache[K, V]) victim() *container[K, V] {
+	victim := c.orderList.Back()
+	switch {
+	case c.evictionLess != nil:
+		// walk from LRU to MRU so that ties evict the least recently used entry
+		least := entryInfo[K, V](victim)
+		for element := victim.Prev(); element != nil; element = element.Prev() {
+			info := entryInfo[K, V](element)
+			if c.evictionLess(info, least) {
+				victim, least = element, info
+			}
+		}
+	case c.referenceBit:
+		// second chance: referenced entries have their bit cleared and are
+		// moved to the front, until an unreferenced one reaches the back
+		for victim.referenced.Swap(false) {
+			c.orderList.MoveToFront(victim)
+			victim = c.orderList.Back()
+		}
+	case c.prioritized:
+		// only the priorityWindow least recently used entries are candidates
+		lowest := entryInfo[K, V](victim).Priority
+		element := victim.Prev()
+		for i := 1; i < c.priorityWindow && element != nil; i, element = i+1, element.Prev() {
+			if priority := entryInfo[K, V](element).Priority; priority < lowest {
+				victim, lowest = element, priority
+			}
+		}
+	}
+	if c.evictionVeto != nil {
+		victim = c.unvetoed(victim)
+	}
+	return victim
+}
+
+// unvetoed walks from victim towards the most recently used entry until the
+// veto callback accepts a candidate. After maxEvictionVetoes vetoes, or when
+// every entry was vetoed, victim is returned so that protecting entries
+// cannot stall inserts.
+func (c *cache[K, V]) unvetoed(victim *container[K, V]) *container[K, V] {
+	candidate := victim
+	for range maxEvictionVetoes {
+		if !c.evictionVeto(candidate.key, candidate.value) {
+			return candidate
+		}
+		if candidate = candidate.Prev(); candidate == nil {
+			break
+		}
+	}
+	return victim
+}
+
+func entryInfo[K comparable, V any](element *container[K, V]) EntryInfo[K, V] {
+	return EntryInfo[K, V]{
+		Key:      element.key,
+		Value:    element.value,
+		Priority: element.priority,
+		Inserted: element.inserted,
+		Hits:     element.hits.Load(),
+	}
 }
 
 func (c *cache[K, V]) Len() int {
@@ -95,40 +848,499 @@ func (c *cache[K, V]) Len() int {
 	return len(c.m)
 }
 
+// RangeMatch calls fn for every entry whose key satisfies match, from most to
+// least recently used, until fn returns false. Both run under the read lock
+// and must not call methods that modify the cache.
+func (c *cache[K, V]) RangeMatch(match func(K) bool, fn func(K, V) bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for element := c.orderList.Front(); element != nil; element = element.Next() {
+		if !match(element.key) {
+			continue
+		}
+		if !fn(element.key, element.value) {
+			return
+		}
+	}
+}
+
+// RangeLimit calls fn for at most n entries from the most recently used end,
+// until fn returns false. Bounding the walk bounds how long the read lock is
+// held, so sampling the hot set of a large cache does not stall writers. fn
+// runs under the read lock and must not call methods that modify the cache.
+func (c *cache[K, V]) RangeLimit(n int, fn func(K, V) bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for element := c.orderList.Front(); element != nil && n > 0; element, n = element.Next(), n-1 {
+		if !fn(element.key, element.value) {
+			return
+		}
+	}
+}
+
+// OrderIter returns an iterator over the keys from most to least recently
+// used, without changing recency or stats. The keys are copied under the read
+// lock when the loop starts and yielded after releasing it, so the loop body
+// may call any method, and changes it makes do not affect the keys visited.
+func (c *cache[K, V]) OrderIter() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		c.lock.RLock()
+		keys := make([]K, 0, c.orderList.Len())
+		for element := c.orderList.Front(); element != nil; element = element.Next() {
+			keys = append(keys, element.key)
+		}
+		c.lock.RUnlock()
+
+		for _, key := range keys {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// ContainsAll reports whether every key is cached, checked under a single read
+// lock without changing recency or stats. It is true when no keys are given.
+func (c *cache[K, V]) ContainsAll(keys ...K) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, key := range keys {
+		if _, ok := c.m[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsAny reports whether at least one key is cached, checked under a
+// single read lock without changing recency or stats.
+func (c *cache[K, V]) ContainsAny(keys ...K) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for _, key := range keys {
+		if _, ok := c.m[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Coldest returns up to n keys from the least recently used end, least
+// recently used first, without changing recency or stats
+func (c *cache[K, V]) Coldest(n int) []K {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	keys := make([]K, 0, min(max(n, 0), len(c.m)))
+	for element := c.orderList.Back(); element != nil && len(keys) < n; element = element.Prev() {
+		keys = append(keys, element.key)
+	}
+	return keys
+}
+
+// Hottest returns up to n keys from the most recently used end, most recently
+// used first, without changing recency or stats
+func (c *cache[K, V]) Hottest(n int) []K {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	keys := make([]K, 0, min(max(n, 0), len(c.m)))
+	for element := c.orderList.Front(); element != nil && len(keys) < n; element = element.Next() {
+		keys = append(keys, element.key)
+	}
+	return keys
+}
+
+// CopyTo puts every entry into dst, from least to most recently used, so that
+// the recency order carries over and a smaller dst keeps the most recently
+// used entries. This cache is read locked throughout, without changing
+// recency or stats, so dst must not be this cache, which would deadlock.
+func (c *cache[K, V]) CopyTo(dst Cache[K, V]) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for element := c.orderList.Back(); element != nil; element = element.Prev() {
+		dst.Put(element.key, element.value)
+	}
+}
+
+// Capacity returns the maximum number of entries, or 0 for an unbounded cache
+func (c *cache[K, V]) Capacity() uint {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.capacity
+}
+
+// Utilization returns Len()/Capacity(), both read under the same lock so the
+// result stays within [0, 1]. It is always 0 for an unbounded cache.
+func (c *cache[K, V]) Utilization() float64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.capacity == 0 {
+		return 0
+	}
+	return float64(len(c.m)) / float64(c.capacity)
+}
+
 func (c *cache[K, V]) Delete(key K) {
-	c.lock.Lock()
+	c.lockWritable()
 	defer c.lock.Unlock()
 
-	val, ok := c.m[key]
+	element, ok := c.m[key]
 	if !ok {
 		return
 	}
-	delete(c.m, key)
-	c.orderList.Remove(val)
+	c.remove(element)
+}
+
+// DeleteMulti removes the given keys under a single write lock and returns
+// how many of them were cached. Keys given more than once are counted once.
+func (c *cache[K, V]) DeleteMulti(keys ...K) int {
+	c.lockWritable()
+	defer c.lock.Unlock()
+
+	deleted := 0
+	for _, key := range keys {
+		if element, ok := c.m[key]; ok {
+			c.remove(element)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// CompareAndDelete removes key only if its value equals old, like
+// CompareAndDelete of sync.Map. Values are compared with the WithEqualFunc
+// function, or else with ==, which panics when V holds an uncomparable type
+// such as a slice or map.
+func (c *cache[K, V]) CompareAndDelete(key K, old V) (deleted bool) {
+	c.lockWritable()
+	defer c.lock.Unlock()
+
+	element, ok := c.m[key]
+	if !ok {
+		return false
+	}
+
+	if !c.valuesEqual(element.value, old) {
+		return false
+	}
+
+	c.remove(element)
+	return true
+}
+
+// PopIf removes and returns the least recently used entry if pred reports
+// true for it, all under the write lock. Otherwise, or if the cache is empty,
+// it returns false and leaves the cache unchanged. pred must not call other
+// cache methods.
+func (c *cache[K, V]) PopIf(pred func(K, V) bool) (key K, value V, ok bool) {
+	c.lockWritable()
+	defer c.lock.Unlock()
+
+	element := c.orderList.Back()
+	if element == nil {
+		return key, value, false
+	}
+	if !pred(element.key, element.value) {
+		return key, value, false
+	}
+	c.remove(element)
+	return element.key, element.value, true
+}
+
+// RangeUpdate calls fn for every entry, from most to least recently used,
+// and stores the value it returns. Entries for which fn returns false are
+// deleted. It runs under the write lock and does not change recency, so fn
+// must not call other cache methods.
+func (c *cache[K, V]) RangeUpdate(fn func(K, V) (V, bool)) {
+	c.lockWritable()
+	defer c.lock.Unlock()
+
+	for element := c.orderList.Front(); element != nil; {
+		// remember the next element, as element may be removed
+		next := element.Next()
+		value, keep := fn(element.key, element.value)
+		if keep {
+			element.value = value
+			element.version++
+		} else {
+			c.remove(element)
+		}
+		element = next
+	}
+}
+
+// RetainTop evicts all but the n most recently used entries and returns how
+// many were evicted. Evictions are recorded with ReasonManual.
+func (c *cache[K, V]) RetainTop(n int) int {
+	c.lockWritable()
+	defer c.unlock()
+
+	evicted := 0
+	for len(c.m) > max(n, 0) {
+		c.evictElement(c.orderList.Back(), ReasonManual)
+		evicted++
+	}
+	return evicted
+}
+
+// Diff compares the cache with a previously captured map of its entries.
+// added holds the keys cached now but absent from previous, changed the keys
+// in both whose values differ, both from most to least recently used, and
+// removed the keys of previous no longer cached, in unspecified order. Values
+// are compared like in CompareAndDelete, so without WithEqualFunc Diff panics
+// when V holds an uncomparable type.
+func (c *cache[K, V]) Diff(previous map[K]V) (added, removed, changed []K) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	for element := c.orderList.Front(); element != nil; element = element.Next() {
+		old, ok := previous[element.key]
+		switch {
+		case !ok:
+			added = append(added, element.key)
+		case !c.valuesEqual(old, element.value):
+			changed = append(changed, element.key)
+		}
+	}
+	for key := range previous {
+		if _, ok := c.m[key]; !ok {
+			removed = append(removed, key)
+		}
+	}
+	return added, removed, changed
+}
+
+// valuesEqual compares with the configured equality func, falling back to
+// interface comparison which panics if V holds an uncomparable type
+func (c *cache[K, V]) valuesEqual(a, b V) bool {
+	if c.equal != nil {
+		return c.equal(a, b)
+	}
+	return any(a) == any(b)
+}
+
+// ShrinkToFit rebuilds the backing map at its current size. Go maps never
+// shrink, so after a burst followed by mass deletion this releases the memory
+// held by the old map. Recency order and statistics are preserved.
+func (c *cache[K, V]) ShrinkToFit() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	m := make(map[K]*container[K, V], len(c.m))
+	maps.Copy(m, c.m)
+	c.m = m
+}
+
+// Reserve rebuilds the backing map with room for at least n entries, capped at
+// the capacity, so that a known burst of inserts does not rehash it midway.
+// It does nothing when n entries are already cached. Eviction capacity,
+// recency order and statistics are unchanged.
+func (c *cache[K, V]) Reserve(n uint) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.capacity != 0 {
+		n = min(n, c.capacity)
+	}
+	if n <= uint(len(c.m)) {
+		return
+	}
+	// n is a uint and may not fit the int size hint of make
+	m := make(map[K]*container[K, V], int(min(n, math.MaxInt)))
+	maps.Copy(m, c.m)
+	c.m = m
 }
 
 func (c *cache[K, V]) Stats() (hits uint64, misses uint64, evictions uint64) {
 	return c.stats.hits.Load(), c.stats.misses.Load(), c.stats.evictions.Load()
 }
 
+// StatsStruct returns the statistics together with the current length and
+// capacity, the latter two read under one lock so that they are consistent
+// with each other.
+func (c *cache[K, V]) StatsStruct() StatsSnapshot {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	return StatsSnapshot{
+		Hits:          c.stats.hits.Load(),
+		Misses:        c.stats.misses.Load(),
+		Evictions:     c.stats.evictions.Load(),
+		Insertions:    c.stats.insertions.Load(),
+		Updates:       c.stats.updates.Load(),
+		LoadSuccesses: c.stats.loadSuccesses.Load(),
+		LoadFailures:  c.stats.loadFailures.Load(),
+		Length:        len(c.m),
+		Capacity:      c.capacity,
+	}
+}
+
+// ComputeCount returns how many times a loader or compute function passed to
+// the GetOrCompute family or Warm actually ran since the cache was created or
+// last cleared. Callers that shared another caller's computation, and hits,
+// are not counted.
+func (c *cache[K, V]) ComputeCount() uint64 {
+	return c.stats.computations.Load()
+}
+
+// WindowedHitRatio returns the share of hits among the last lookups tracked
+// by WithHitRateWindow, or 0 before the first lookup or when it is not
+// enabled. Unlike the lifetime ratio from Stats it reflects recent changes.
+func (c *cache[K, V]) WindowedHitRatio() float64 {
+	if c.hitWindow == nil {
+		return 0
+	}
+	return c.hitWindow.ratio()
+}
+
+// PeakLen returns the largest number of entries the cache has held since it
+// was created or last cleared. Compared with Capacity it shows how much of
+// the capacity was ever used, and for an unbounded cache how large it grew.
+func (c *cache[K, V]) PeakLen() uint64 {
+	return c.stats.peakLen.Load()
+}
+
+// GhostHits returns how many misses were for keys still remembered by the
+// ghost list, i.e. misses that a larger cache would have served as hits
+func (c *cache[K, V]) GhostHits() uint64 {
+	return c.stats.ghostHits.Load()
+}
+
 func (c *cache[K, V]) Clear() {
-	c.lock.Lock()
+	c.lockWritable()
+	defer c.lock.Unlock()
+
+	c.clear()
+}
+
+// ClearAndRelease is Clear that also releases the memory of the backing map,
+// replacing it with a new one sized as at construction. Clear keeps the map's
+// storage for reuse, which is faster when the cache will fill up again.
+func (c *cache[K, V]) ClearAndRelease() {
+	c.lockWritable()
+	defer c.lock.Unlock()
+
+	c.clear()
+	c.m = c.newMap()
+}
+
+// clear must be called with the write lock held
+func (c *cache[K, V]) clear() {
+	c.stats.reset()
+	if c.defaults != nil {
+		c.defaults.clear()
+	}
+	if c.failures != nil {
+		c.failures.clear()
+	}
+	c.evictionRate.reset(c.now())
+	if c.audit != nil {
+		c.audit.gets.Store(0)
+		c.audit.puts.Store(0)
+	}
+	clear(c.m)
+	c.orderList.Init()
+	if c.tags != nil {
+		c.tags.clear()
+	}
+	if c.ghosts != nil {
+		c.ghosts.clear()
+	}
+	if c.hitWindow != nil {
+		c.hitWindow.reset()
+	}
+}
+
+// Drain removes and returns every entry, most recently used first, under a
+// single write lock. Unlike reading the entries and then calling Clear, no
+// concurrent Put can slip in between and be lost. Statistics are kept.
+func (c *cache[K, V]) Drain() []Pair[K, V] {
+	c.lockWritable()
 	defer c.lock.Unlock()
 
-	c.stats = stats{}
+	pairs := make([]Pair[K, V], 0, len(c.m))
+	for element := c.orderList.Front(); element != nil; element = element.Next() {
+		pairs = append(pairs, Pair[K, V]{Key: element.key, Value: element.value})
+	}
 	clear(c.m)
 	c.orderList.Init()
+	if c.tags != nil {
+		c.tags.clear()
+	}
+	return pairs
 }
 
-func New[K comparable, V any](capacity uint) (*cache[K, V], error) {
+// Purge removes all items and resets all statistics like Clear, and also
+// calls the WithOnEvict callback for every removed entry with ReasonCleared,
+// from most to least recently used, once the lock is released.
+func (c *cache[K, V]) Purge() {
+	c.lockWritable()
+	defer c.unlock()
+
+	if c.onEvict != nil {
+		for element := c.orderList.Front(); element != nil; element = element.Next() {
+			c.pendingEvicted = append(c.pendingEvicted, evictedEntry[K, V]{key: element.key, value: element.value, reason: ReasonCleared})
+		}
+	}
+	c.clear()
+}
+
+func New[K comparable, V any](capacity uint, opts ...Option[K, V]) (*cache[K, V], error) {
 	if capacity == 0 {
 		return nil, errors.New("capacity should be greater than 0")
 	}
-	return &cache[K, V]{
+	return newCache(capacity, opts...), nil
+}
+
+// NewUnbounded creates a cache that never evicts: it grows with every new key
+// until entries are deleted or the cache is cleared.
+func NewUnbounded[K comparable, V any](opts ...Option[K, V]) *cache[K, V] {
+	return newCache(0, opts...)
+}
+
+func newCache[K comparable, V any](capacity uint, opts ...Option[K, V]) *cache[K, V] {
+	c := &cache[K, V]{
 		capacity:  capacity,
-		orderList: list.New(),
-		m:         make(map[K]*list.Element, capacity),
+		orderList: &entryList[K, V]{},
 
 		stats: stats{},
-	}, nil
+
+		initialMapSize: capacity,
+		priorityWindow: defaultPriorityWindow,
+		evictBatch:     1,
+
+		now: time.Now,
+	}
+	c.unfrozen = sync.NewCond(&c.lock)
+	for _, opt := range opts {
+		opt(c)
+	}
+	// map is allocated after options so that the size hint can be applied
+	if capacity != 0 {
+		c.initialMapSize = min(c.initialMapSize, capacity)
+	}
+	c.m = c.newMap()
+	c.evictionRate.reset(c.now())
+	if c.ghosts != nil && c.ghostTTL > 0 {
+		c.ghosts.ttl = c.ghostTTL
+		// read on every call so that the clock can be replaced in tests
+		c.ghosts.now = func() time.Time { return c.now() }
+	}
+	if c.statsLog != nil {
+		c.statsLog.start(c.StatsStruct)
+	}
+	return c
+}
+
+// newMap allocates a key map sized to the initial map size
+func (c *cache[K, V]) newMap() map[K]*container[K, V] {
+	// capacity is a uint and may not fit the int size hint of make
+	return make(map[K]*container[K, V], int(min(c.initialMapSize, math.MaxInt)))
 }
@@ -1,9 +1,17 @@
 package lrucache
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestZeroCapacity(t *testing.T) {
@@ -222,6 +230,1920 @@ func TestStatsConcurrency(t *testing.T) {
 	wg.Wait()
 }
 
+func TestCompareAndDelete(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, string](2)
+	cache.Put("key", "value")
+
+	if cache.CompareAndDelete("key", "other") {
+		t.Error("CompareAndDelete should not delete when value does not match")
+	}
+	if _, ok := cache.Get("key"); !ok {
+		t.Error("expected key to exists after non-matching CompareAndDelete, but do not exists")
+	}
+
+	if !cache.CompareAndDelete("key", "value") {
+		t.Error("CompareAndDelete should delete when value matches")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("expected cache length to be 0, but got: %d", cache.Len())
+	}
+
+	if cache.CompareAndDelete("missing", "value") {
+		t.Error("CompareAndDelete should return false for absent key")
+	}
+}
+
+func TestCompareAndDeleteEqualFunc(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(1, WithEqualFunc[string](slices.Equal[[]int]))
+	cache.Put("key", []int{1, 2})
+
+	if cache.CompareAndDelete("key", []int{1, 3}) {
+		t.Error("CompareAndDelete should not delete when value does not match")
+	}
+	if !cache.CompareAndDelete("key", []int{1, 2}) {
+		t.Error("CompareAndDelete should delete when value matches")
+	}
+}
+
+func TestInitialMapSize(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(100, WithInitialMapSize[int, int](1))
+
+	for i := range 150 {
+		cache.Put(i, i)
+	}
+
+	if cache.Len() != 100 {
+		t.Errorf("expected cache length to be 100, but got: %d", cache.Len())
+	}
+	for i := 50; i < 150; i++ {
+		val, ok := cache.Get(i)
+		if !ok || val != i {
+			t.Errorf("expected key: %d to exists with value: %d, but got: %d, %t", i, i, val, ok)
+		}
+	}
+	_, _, evictions := cache.Stats()
+	if evictions != 50 {
+		t.Errorf("expected evictions to be 50, but got: %d", evictions)
+	}
+}
+
+func TestEvictionComparator(t *testing.T) {
+	t.Parallel()
+	// treat the value length as the entry cost and evict the largest entry
+	largestFirst := func(a, b EntryInfo[string, string]) bool {
+		return len(a.Value) > len(b.Value)
+	}
+	cache, _ := New(3, WithEvictionComparator(largestFirst))
+
+	cache.Put("small", "a")
+	cache.Put("large", "aaaaaaaa")
+	cache.Put("medium", "aaaa")
+	cache.Get("large") // recency must not protect the largest entry
+	cache.Put("new", "aa")
+
+	if _, ok := cache.Get("large"); ok {
+		t.Error("key: `large` should have been evicted, but still exists")
+	}
+	for _, key := range []string{"small", "medium", "new"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected key: `%s` to exists, but do not exists", key)
+		}
+	}
+
+	_, _, evictions := cache.Stats()
+	if evictions != 1 {
+		t.Errorf("expected evictions to be 1, but got: %d", evictions)
+	}
+}
+
+func TestEvictionComparatorEntryInfo(t *testing.T) {
+	t.Parallel()
+	oldestFirst := func(a, b EntryInfo[string, int]) bool {
+		return a.Inserted < b.Inserted
+	}
+	cache, _ := New(3, WithEvictionComparator(oldestFirst))
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.Put("c", 3)
+	cache.Get("a")
+	cache.Put("a", 10) // updates keep the insertion order
+	cache.Put("d", 4)
+	if cache.Contains("a") {
+		t.Error("key: `a` should have been evicted as the oldest insert, but still exists")
+	}
+
+	leastAccessed := func(a, b EntryInfo[string, int]) bool {
+		return a.Hits < b.Hits
+	}
+	cache, _ = New(3, WithEvictionComparator(leastAccessed))
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.Put("c", 3)
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("c")
+	cache.Get("c")
+	cache.GetOrCompute("b", func() (int, error) { return 0, nil })
+	cache.Put("d", 4)
+	if cache.Contains("b") {
+		t.Error("key: `b` should have been evicted as the least accessed, but still exists")
+	}
+	if !cache.Contains("a") || !cache.Contains("c") {
+		t.Error("expected the most accessed keys `a` and `c` to be kept")
+	}
+}
+
+func TestUtilization(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](4)
+
+	if cache.Utilization() != 0 {
+		t.Errorf("expected utilization to be 0, but got: %f", cache.Utilization())
+	}
+	cache.Put(1, 1)
+	cache.Put(2, 2)
+	if cache.Utilization() != 0.5 {
+		t.Errorf("expected utilization to be 0.5, but got: %f", cache.Utilization())
+	}
+	for i := range 10 {
+		cache.Put(i, i)
+	}
+	if cache.Utilization() != 1 {
+		t.Errorf("expected utilization to be 1, but got: %f", cache.Utilization())
+	}
+	if cache.Capacity() != 4 {
+		t.Errorf("expected capacity to be 4, but got: %d", cache.Capacity())
+	}
+}
+
+func TestEvictionLog(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(1, WithEvictionLog[int, int](3))
+
+	if events := cache.RecentEvictions(); len(events) != 0 {
+		t.Errorf("expected no eviction events, but got: %d", len(events))
+	}
+
+	// keys 0..4 get evicted in order, the log keeps only the last 3
+	for i := range 6 {
+		cache.Put(i, i)
+	}
+
+	events := cache.RecentEvictions()
+	if len(events) != 3 {
+		t.Fatalf("expected 3 eviction events, but got: %d", len(events))
+	}
+	for i, event := range events {
+		if event.Key != i+2 {
+			t.Errorf("expected event %d to be for key: %d, but got: %d", i, i+2, event.Key)
+		}
+		if event.Reason != ReasonCapacity {
+			t.Errorf("expected event reason to be %s, but got: %s", ReasonCapacity, event.Reason)
+		}
+		if event.Time.IsZero() {
+			t.Errorf("expected event %d to have a timestamp", i)
+		}
+	}
+}
+
+func TestEvictionLogDisabled(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](1)
+	cache.Put(1, 1)
+	cache.Put(2, 2)
+
+	if events := cache.RecentEvictions(); events != nil {
+		t.Errorf("expected nil eviction events when log is disabled, but got: %v", events)
+	}
+}
+
+func TestPutAll(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](5)
+	cache.Put("old-1", 1)
+	cache.Put("old-2", 2)
+	cache.Put("old-3", 3)
+
+	items := map[string]int{"new-1": 1, "new-2": 2, "new-3": 3, "new-4": 4}
+	evicted := cache.PutAll(items)
+
+	if len(evicted) != 2 {
+		t.Fatalf("expected 2 evicted keys, but got: %d", len(evicted))
+	}
+	for _, key := range evicted {
+		if _, ok := items[key]; ok {
+			t.Errorf("newly inserted key: `%s` should not have been evicted", key)
+		}
+		if _, ok := cache.Get(key); ok {
+			t.Errorf("evicted key: `%s` should not exists in cache", key)
+		}
+	}
+	for key, value := range items {
+		if got, ok := cache.Get(key); !ok || got != value {
+			t.Errorf("expected key: `%s` to exists with value: %d, but got: %d, %t", key, value, got, ok)
+		}
+	}
+}
+
+func TestPutAllLargerThanCapacity(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](2)
+
+	evicted := cache.PutAll(map[int]int{1: 1, 2: 2, 3: 3, 4: 4, 5: 5})
+	if len(evicted) != 3 {
+		t.Errorf("expected 3 evicted keys, but got: %d", len(evicted))
+	}
+	if cache.Len() != 2 {
+		t.Errorf("expected cache length to be 2, but got: %d", cache.Len())
+	}
+}
+
+func TestGhostHits(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(2, WithGhostList[string, string](2))
+
+	cache.Put("a", "1")
+	cache.Put("b", "2")
+	cache.Put("c", "3") // evicts "a" into the ghost list
+	cache.Put("d", "4") // evicts "b" into the ghost list
+
+	cache.Get("a") // ghost hit, would have been a hit with capacity 3
+	cache.Get("a") // "a" is forgotten after its ghost hit
+	cache.Get("z") // never cached
+	cache.Get("c") // regular hit
+
+	if ghostHits := cache.GhostHits(); ghostHits != 1 {
+		t.Errorf("expected ghost hits to be 1, but got: %d", ghostHits)
+	}
+
+	// re-inserting a ghost key removes it from the ghost list
+	cache.Put("b", "2")
+	cache.Delete("b")
+	cache.Get("b")
+	if ghostHits := cache.GhostHits(); ghostHits != 1 {
+		t.Errorf("expected ghost hits to be 1, but got: %d", ghostHits)
+	}
+
+	hits, misses, _ := cache.Stats()
+	if hits != 1 {
+		t.Errorf("expected hits to be 1, but got: %d", hits)
+	}
+	if misses != 4 {
+		t.Errorf("expected misses to be 4, but got: %d", misses)
+	}
+}
+
+func TestRangeMatch(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](10)
+	cache.Put("user:1:profile", 1)
+	cache.Put("order:1", 2)
+	cache.Put("user:2:profile", 3)
+	cache.Put("order:2", 4)
+
+	var visited []string
+	cache.RangeMatch(func(key string) bool {
+		return strings.HasPrefix(key, "user:")
+	}, func(key string, value int) bool {
+		visited = append(visited, key)
+		return true
+	})
+
+	// entries are visited from most to least recently used
+	expected := []string{"user:2:profile", "user:1:profile"}
+	if !slices.Equal(visited, expected) {
+		t.Errorf("expected visited keys to be %v, but got: %v", expected, visited)
+	}
+
+	visited = nil
+	cache.RangeMatch(func(string) bool { return true }, func(key string, value int) bool {
+		visited = append(visited, key)
+		return len(visited) < 2
+	})
+	if len(visited) != 2 {
+		t.Errorf("expected iteration to stop after 2 entries, but visited: %d", len(visited))
+	}
+}
+
+func TestPutPairsLastWins(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](2)
+
+	cache.PutPairs([]Pair[string, int]{
+		{Key: "a", Value: 1},
+		{Key: "b", Value: 2},
+		{Key: "a", Value: 3},
+	})
+
+	if cache.Len() != 2 {
+		t.Errorf("expected cache length to be 2, but got: %d", cache.Len())
+	}
+
+	// the last occurrence of "a" made it the most recently used entry
+	cache.Put("c", 4)
+	if _, ok := cache.Get("b"); ok {
+		t.Error("key: `b` should have been evicted, but still exists")
+	}
+	val, ok := cache.Get("a")
+	if !ok {
+		t.Fatal("expected key: `a` to exists, but do not exists")
+	}
+	if val != 3 {
+		t.Errorf("expected value to be 3, but got: %d", val)
+	}
+}
+
+func TestShrinkToFit(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(10000, WithInitialMapSize[int, int](1))
+
+	for i := range 10000 {
+		cache.Put(i, i)
+	}
+	for i := range 9997 {
+		cache.Delete(i)
+	}
+	cache.Get(9997)
+
+	cache.ShrinkToFit()
+
+	if cache.Len() != 3 {
+		t.Errorf("expected cache length to be 3, but got: %d", cache.Len())
+	}
+	for i := 9997; i < 10000; i++ {
+		if val, ok := cache.Get(i); !ok || val != i {
+			t.Errorf("expected key: %d to exists with value: %d, but got: %d, %t", i, i, val, ok)
+		}
+	}
+	hits, _, _ := cache.Stats()
+	if hits != 4 {
+		t.Errorf("expected hits to be 4, but got: %d", hits)
+	}
+
+	// recency order is preserved across the rebuild
+	fill, _ := New(3, WithInitialMapSize[int, int](1))
+	fill.Put(1, 1)
+	fill.Put(2, 2)
+	fill.Put(3, 3)
+	fill.Get(1)
+	fill.ShrinkToFit()
+	fill.Put(4, 4)
+	if _, ok := fill.Get(2); ok {
+		t.Error("key: 2 should have been evicted after ShrinkToFit, but still exists")
+	}
+}
+
+func TestVersion(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, string](2)
+
+	if _, ok := cache.Version("key"); ok {
+		t.Error("Version should report false for absent key")
+	}
+
+	cache.Put("key", "v1")
+	cache.Put("key", "v2")
+	cache.PutPairs([]Pair[string, string]{{Key: "key", Value: "v3"}})
+
+	version, ok := cache.Version("key")
+	if !ok {
+		t.Fatal("expected key to exists, but do not exists")
+	}
+	if version != 3 {
+		t.Errorf("expected version to be 3, but got: %d", version)
+	}
+
+	cache.Delete("key")
+	cache.Put("key", "v1")
+	if version, _ := cache.Version("key"); version != 1 {
+		t.Errorf("expected version to restart at 1 after delete, but got: %d", version)
+	}
+}
+
+func TestPutIfVersion(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, string](2)
+
+	if cache.PutIfVersion("key", "v1", 1) {
+		t.Error("PutIfVersion should fail for absent key with non-zero version")
+	}
+	if !cache.PutIfVersion("key", "v1", 0) {
+		t.Error("PutIfVersion should insert absent key with version 0")
+	}
+	if cache.PutIfVersion("key", "stale", 0) {
+		t.Error("PutIfVersion should fail when version does not match")
+	}
+	if !cache.PutIfVersion("key", "v2", 1) {
+		t.Error("PutIfVersion should succeed when version matches")
+	}
+
+	val, _ := cache.Get("key")
+	if val != "v2" {
+		t.Errorf("expected value to be `v2`, but got: `%s`", val)
+	}
+	if version, _ := cache.Version("key"); version != 2 {
+		t.Errorf("expected version to be 2, but got: %d", version)
+	}
+}
+
+func TestPutIfVersionNotStored(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(2, WithSkipEqualPuts[string, string](func(a, b string) bool { return a == b }))
+	cache.Put("key", "v1")
+	if cache.PutIfVersion("key", "v1", 1) {
+		t.Error("PutIfVersion should report false when an equal value is skipped")
+	}
+	if version, _ := cache.Version("key"); version != 1 {
+		t.Errorf("expected version to stay 1, but got: %d", version)
+	}
+
+	limited, _ := New(2, WithMaxKeySize[string, string](3, nil))
+	if limited.PutIfVersion("too-long", "v1", 0) {
+		t.Error("PutIfVersion should report false when the key is too large")
+	}
+	if limited.Contains("too-long") {
+		t.Error("expected the too large key not to be cached")
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, string](2)
+
+	if err := cache.SetValue("key", "value"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("expected error to be %v, but got: %v", ErrKeyNotFound, err)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("SetValue should not insert absent key, but cache length is: %d", cache.Len())
+	}
+
+	cache.Put("key", "value")
+	cache.Put("other", "value")
+	if err := cache.SetValue("key", "updated"); err != nil {
+		t.Errorf("expected no error, but got: %v", err)
+	}
+
+	// "key" was promoted, so "other" is the one evicted
+	cache.Put("new", "value")
+	val, ok := cache.Get("key")
+	if !ok || val != "updated" {
+		t.Errorf("expected value to be `updated`, but got: `%s`, %t", val, ok)
+	}
+	if _, ok := cache.Get("other"); ok {
+		t.Error("key: `other` should have been evicted, but still exists")
+	}
+}
+
+func TestStableOrder(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(2, WithStableOrder[string, string]())
+
+	cache.Put("a", "1")
+	cache.Put("b", "2")
+	cache.Put("a", "updated") // does not change eviction priority of "a"
+	cache.Put("c", "3")
+
+	if _, ok := cache.Get("a"); ok {
+		t.Error("key: `a` should have been evicted despite its update, but still exists")
+	}
+	if _, ok := cache.Get("b"); !ok {
+		t.Error("expected key: `b` to exists, but do not exists")
+	}
+
+	// reads still promote
+	cache.Get("c")
+	cache.Get("b")
+	cache.Put("d", "4")
+	if _, ok := cache.Get("c"); ok {
+		t.Error("key: `c` should have been evicted, but still exists")
+	}
+}
+
+func TestUnbounded(t *testing.T) {
+	t.Parallel()
+	cache := NewUnbounded[int, int]()
+
+	for i := range 10000 {
+		cache.Put(i, i)
+	}
+
+	if cache.Len() != 10000 {
+		t.Errorf("expected cache length to be 10000, but got: %d", cache.Len())
+	}
+	if val, ok := cache.Get(0); !ok || val != 0 {
+		t.Errorf("expected first key to exists, but got: %d, %t", val, ok)
+	}
+	_, _, evictions := cache.Stats()
+	if evictions != 0 {
+		t.Errorf("expected evictions to be 0, but got: %d", evictions)
+	}
+	if cache.Capacity() != 0 {
+		t.Errorf("expected capacity to be 0, but got: %d", cache.Capacity())
+	}
+	if cache.Utilization() != 0 {
+		t.Errorf("expected utilization to be 0, but got: %f", cache.Utilization())
+	}
+}
+
+func TestEvictionCallbackEvery(t *testing.T) {
+	t.Parallel()
+	var snapshots []StatsSnapshot
+	var cache *cache[int, int]
+	cache, _ = New(10, WithEvictionCallbackEvery[int, int](5, func(s StatsSnapshot) {
+		// the callback runs without the lock, so it may use the cache
+		_ = cache.Len()
+		snapshots = append(snapshots, s)
+	}))
+
+	cache.Get(-1)
+	for i := range 21 {
+		cache.Put(i, i)
+	}
+
+	// 21 puts into a capacity of 10 evict 11 entries, crossing 5 and 10
+	if len(snapshots) != 2 {
+		t.Fatalf("expected callback to fire 2 times, but got: %d", len(snapshots))
+	}
+	for i, snapshot := range snapshots {
+		if snapshot.Evictions != uint64(5*(i+1)) {
+			t.Errorf("expected snapshot %d evictions to be %d, but got: %d", i, 5*(i+1), snapshot.Evictions)
+		}
+		if snapshot.Misses != 1 {
+			t.Errorf("expected snapshot %d misses to be 1, but got: %d", i, snapshot.Misses)
+		}
+	}
+}
+
+func TestEvictionCallbackEveryConcurrency(t *testing.T) {
+	t.Parallel()
+	var fired atomic.Int32
+	cache, _ := New(10, WithEvictionCallbackEvery[int, int](10, func(StatsSnapshot) {
+		fired.Add(1)
+	}))
+
+	var wg sync.WaitGroup
+	for i := range 1010 {
+		wg.Go(func() {
+			cache.Put(i, i)
+		})
+	}
+	wg.Wait()
+
+	// 1000 evictions cross 100 multiples of 10
+	if fired.Load() != 100 {
+		t.Errorf("expected callback to fire 100 times, but got: %d", fired.Load())
+	}
+}
+
+func TestColdest(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](5)
+	for i, key := range []string{"a", "b", "c", "d", "e"} {
+		cache.Put(key, i)
+	}
+	cache.Get("a")
+	cache.Get("c")
+
+	// order from LRU to MRU is now b, d, e, a, c
+	coldest := cache.Coldest(3)
+	expected := []string{"b", "d", "e"}
+	if !slices.Equal(coldest, expected) {
+		t.Errorf("expected coldest keys to be %v, but got: %v", expected, coldest)
+	}
+	if len(cache.Coldest(10)) != 5 {
+		t.Errorf("expected coldest to return all 5 keys, but got: %d", len(cache.Coldest(10)))
+	}
+	if len(cache.Coldest(0)) != 0 {
+		t.Errorf("expected coldest(0) to be empty, but got: %v", cache.Coldest(0))
+	}
+
+	// Coldest must not promote, so "b" is still evicted first
+	cache.Put("f", 5)
+	if _, ok := cache.Get("b"); ok {
+		t.Error("key: `b` should have been evicted, but still exists")
+	}
+}
+
+func TestHottest(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](5)
+	for i, key := range []string{"a", "b", "c", "d", "e"} {
+		cache.Put(key, i)
+	}
+	cache.Get("b")
+	cache.Put("a", 10)
+	cache.Get("d")
+
+	hottest := cache.Hottest(3)
+	expected := []string{"d", "a", "b"}
+	if !slices.Equal(hottest, expected) {
+		t.Errorf("expected hottest keys to be %v, but got: %v", expected, hottest)
+	}
+	if len(cache.Hottest(10)) != 5 {
+		t.Errorf("expected hottest to return all 5 keys, but got: %d", len(cache.Hottest(10)))
+	}
+	if len(cache.Hottest(-1)) != 0 {
+		t.Errorf("expected hottest(-1) to be empty, but got: %v", cache.Hottest(-1))
+	}
+}
+
+func TestCapacityBoundary(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](3)
+
+	for i := range 3 {
+		cache.Put(i, i)
+	}
+	if _, _, evictions := cache.Stats(); evictions != 0 {
+		t.Errorf("expected no evictions when filled exactly to capacity, but got: %d", evictions)
+	}
+	if cache.Len() != 3 {
+		t.Errorf("expected cache length to be 3, but got: %d", cache.Len())
+	}
+
+	cache.Put(3, 3)
+	if _, _, evictions := cache.Stats(); evictions != 1 {
+		t.Errorf("expected 1 eviction past capacity, but got: %d", evictions)
+	}
+	if cache.Len() != 3 {
+		t.Errorf("expected cache length to be 3, but got: %d", cache.Len())
+	}
+
+	// a length above capacity must still trigger eviction
+	cache.capacity = 2
+	cache.Put(4, 4)
+	if _, _, evictions := cache.Stats(); evictions != 2 {
+		t.Errorf("expected eviction when length exceeds capacity, but got evictions: %d", evictions)
+	}
+}
+
+func TestLargeCapacity(t *testing.T) {
+	t.Parallel()
+	cache, err := New[int, int](math.MaxUint)
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	cache.Put(1, 1)
+	if val, ok := cache.Get(1); !ok || val != 1 {
+		t.Errorf("expected key: 1 to exists with value: 1, but got: %d, %t", val, ok)
+	}
+
+	arc, err := NewARC[int, int](math.MaxUint)
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+	arc.Put(1, 1)
+	if val, ok := arc.Get(1); !ok || val != 1 {
+		t.Errorf("expected key: 1 to exists with value: 1, but got: %d, %t", val, ok)
+	}
+}
+
+func TestOnAccess(t *testing.T) {
+	t.Parallel()
+	type access struct {
+		key string
+		hit bool
+	}
+	var accesses []access
+	var cache *cache[string, string]
+	cache, _ = New(2, WithOnAccess[string, string](func(key string, hit bool) {
+		// the hook runs without the lock, so it may use the cache
+		_ = cache.Len()
+		accesses = append(accesses, access{key: key, hit: hit})
+	}))
+
+	cache.Put("a", "1")
+	cache.Put("b", "2")
+	cache.Get("a")
+	cache.Get("a") // served by the front fast path
+	cache.Get("missing")
+
+	expected := []access{{"a", true}, {"a", true}, {"missing", false}}
+	if !slices.Equal(accesses, expected) {
+		t.Errorf("expected accesses to be %v, but got: %v", expected, accesses)
+	}
+}
+
+func TestContainsAllAndAny(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](3)
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+
+	tests := []struct {
+		name     string
+		keys     []string
+		all, any bool
+	}{
+		{name: "all present", keys: []string{"a", "b"}, all: true, any: true},
+		{name: "some present", keys: []string{"a", "z"}, all: false, any: true},
+		{name: "none present", keys: []string{"y", "z"}, all: false, any: false},
+		{name: "no keys", keys: nil, all: true, any: false},
+	}
+	for _, test := range tests {
+		if got := cache.ContainsAll(test.keys...); got != test.all {
+			t.Errorf("%s: expected ContainsAll to be %t, but got: %t", test.name, test.all, got)
+		}
+		if got := cache.ContainsAny(test.keys...); got != test.any {
+			t.Errorf("%s: expected ContainsAny to be %t, but got: %t", test.name, test.any, got)
+		}
+	}
+
+	hits, misses, _ := cache.Stats()
+	if hits != 0 || misses != 0 {
+		t.Errorf("expected membership checks to not count stats, but got hits: %d, misses: %d", hits, misses)
+	}
+}
+
+func TestRename(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, string](3)
+	cache.Put("a", "1")
+	cache.Put("a", "1") // version 2
+	cache.Put("b", "2")
+	cache.Put("c", "3")
+
+	if !cache.Rename("a", "renamed") {
+		t.Fatal("Rename should succeed for present old key and absent new key")
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Error("old key: `a` should not exists after rename")
+	}
+	if version, _ := cache.Version("renamed"); version != 2 {
+		t.Errorf("expected version to be preserved as 2, but got: %d", version)
+	}
+
+	// "renamed" kept the LRU position of "a" and is evicted first
+	if coldest := cache.Coldest(1); !slices.Equal(coldest, []string{"renamed"}) {
+		t.Errorf("expected coldest key to be `renamed`, but got: %v", coldest)
+	}
+	cache.Put("d", "4")
+	if _, ok := cache.Get("renamed"); ok {
+		t.Error("key: `renamed` should have been evicted, but still exists")
+	}
+}
+
+func TestRenameFailures(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, string](3)
+	cache.Put("a", "1")
+	cache.Put("b", "2")
+
+	if cache.Rename("missing", "new") {
+		t.Error("Rename should fail for absent old key")
+	}
+	if cache.Rename("a", "b") {
+		t.Error("Rename should fail when new key already exists")
+	}
+	if val, _ := cache.Get("a"); val != "1" {
+		t.Errorf("expected key: `a` to keep value `1`, but got: `%s`", val)
+	}
+	if val, _ := cache.Get("b"); val != "2" {
+		t.Errorf("expected key: `b` to keep value `2`, but got: `%s`", val)
+	}
+
+	limited, _ := New(3, WithMaxKeySize[string, string](3, nil))
+	limited.Put("a", "1")
+	if limited.Rename("a", "too-long") {
+		t.Error("Rename should fail when the new key is too large")
+	}
+	if limited.Contains("too-long") || !limited.Contains("a") {
+		t.Error("expected key: `a` to stay under its old key")
+	}
+}
+
+func TestPutWithPriority(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, string](3)
+
+	cache.PutWithPriority("important", "1", 10)
+	cache.Put("b", "2")
+	cache.Put("c", "3")
+	cache.Put("important", "updated") // keeps its priority
+	cache.Get("b")
+	cache.Get("c")
+
+	// "important" is the LRU entry but outranks the others in the window
+	cache.Put("d", "4")
+	if _, ok := cache.Get("important"); !ok {
+		t.Error("high priority key: `important` should have survived eviction")
+	}
+	if _, ok := cache.Get("b"); ok {
+		t.Error("key: `b` should have been evicted as the lowest priority LRU entry")
+	}
+}
+
+func TestPriorityWindow(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(4, WithPriorityWindow[int, int](2))
+
+	cache.Put(1, 1)
+	cache.PutWithPriority(2, 2, 5)
+	cache.Put(3, 3)
+	cache.PutWithPriority(4, 4, 5)
+
+	// the window only covers keys 1 and 2, so key 1 goes first
+	cache.Put(5, 5)
+	if _, ok := cache.Get(1); ok {
+		t.Error("key: 1 should have been evicted, but still exists")
+	}
+	// now keys 2 and 3 are compared, the low priority 3 goes even though 2 is older
+	cache.Put(6, 6)
+	if _, ok := cache.Get(3); ok {
+		t.Error("key: 3 should have been evicted, but still exists")
+	}
+	if _, ok := cache.Get(2); !ok {
+		t.Error("expected key: 2 to exists, but do not exists")
+	}
+}
+
+func TestGetCtx(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, string](2)
+	cache.Put("a", "1")
+	cache.Put("b", "2")
+
+	val, ok, err := cache.GetCtx(context.Background(), "a")
+	if err != nil || !ok || val != "1" {
+		t.Errorf("expected value to be `1`, but got: `%s`, %t, %v", val, ok, err)
+	}
+	_, ok, err = cache.GetCtx(context.Background(), "missing")
+	if err != nil || ok {
+		t.Errorf("expected a miss without error, but got: %t, %v", ok, err)
+	}
+
+	hits, misses, _ := cache.Stats()
+	if hits != 1 || misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, but got hits: %d, misses: %d", hits, misses)
+	}
+}
+
+func TestGetCtxLockTimeout(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, string](2)
+	cache.Put("a", "1")
+	cache.Put("b", "2")
+
+	cache.lock.Lock()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err := cache.GetCtx(ctx, "a")
+	cache.lock.Unlock()
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error to be %v, but got: %v", context.DeadlineExceeded, err)
+	}
+	if _, misses, _ := cache.Stats(); misses != 0 {
+		t.Errorf("expected aborted lookup to not count a miss, but got: %d", misses)
+	}
+}
+
+func TestRangeUpdate(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](5)
+	cache.Put("a", 8)
+	cache.Put("b", 1)
+	cache.Put("c", 4)
+	cache.Put("d", 1)
+
+	cache.RangeUpdate(func(key string, value int) (int, bool) {
+		value /= 2
+		return value, value > 0
+	})
+
+	expected := map[string]int{"a": 4, "c": 2}
+	if cache.Len() != len(expected) {
+		t.Errorf("expected cache length to be %d, but got: %d", len(expected), cache.Len())
+	}
+	for key, value := range expected {
+		if got, ok := cache.Get(key); !ok || got != value {
+			t.Errorf("expected key: `%s` to exists with value: %d, but got: %d, %t", key, value, got, ok)
+		}
+	}
+	for _, key := range []string{"b", "d"} {
+		if _, ok := cache.Get(key); ok {
+			t.Errorf("key: `%s` should have been deleted, but still exists", key)
+		}
+	}
+}
+
+func TestDrain(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](3)
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.Put("c", 3)
+	cache.Get("a")
+
+	pairs := cache.Drain()
+	expected := []Pair[string, int]{{"a", 1}, {"c", 3}, {"b", 2}}
+	if !slices.Equal(pairs, expected) {
+		t.Errorf("expected drained pairs to be %v, but got: %v", expected, pairs)
+	}
+	if cache.Len() != 0 {
+		t.Errorf("expected cache to be empty after drain, but got length: %d", cache.Len())
+	}
+	if _, ok := cache.Get("a"); ok {
+		t.Error("key: `a` should have been drained, but still exists")
+	}
+
+	cache.Put("d", 4)
+	if pairs := cache.Drain(); len(pairs) != 1 {
+		t.Errorf("expected cache to be usable after drain, but got pairs: %v", pairs)
+	}
+}
+
+func TestSkipEqualPuts(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(3, WithSkipEqualPuts[string](func(a, b int) bool { return a == b }))
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.Put("c", 3)
+
+	cache.Put("a", 1)
+	if keys := cache.Hottest(3); !slices.Equal(keys, []string{"c", "b", "a"}) {
+		t.Errorf("expected equal put to keep the order, but got: %v", keys)
+	}
+	if version, _ := cache.Version("a"); version != 1 {
+		t.Errorf("expected equal put to keep version 1, but got: %d", version)
+	}
+
+	cache.Put("a", 10)
+	if keys := cache.Hottest(3); !slices.Equal(keys, []string{"a", "c", "b"}) {
+		t.Errorf("expected different put to promote the key, but got: %v", keys)
+	}
+	if value, _ := cache.Get("a"); value != 10 {
+		t.Errorf("expected value of key: `a` to be 10, but got: %d", value)
+	}
+}
+
+func TestNilPointerValue(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, *int](2)
+	cache.Put("nil", nil)
+
+	value, ok := cache.Get("nil")
+	if !ok {
+		t.Error("expected cached nil pointer to be a hit, but got a miss")
+	}
+	if value != nil {
+		t.Errorf("expected cached value to be nil, but got: %v", value)
+	}
+	if _, ok := cache.Get("absent"); ok {
+		t.Error("expected absent key to be a miss, but got a hit")
+	}
+	if hits, misses, _ := cache.Stats(); hits != 1 || misses != 1 {
+		t.Errorf("expected 1 hit and 1 miss, but got: %d hits, %d misses", hits, misses)
+	}
+
+	// a nil pointer is a regular entry for every other operation too
+	if !cache.ContainsAll("nil") {
+		t.Error("expected key: `nil` to be contained")
+	}
+	if !cache.CompareAndDelete("nil", nil) {
+		t.Error("expected CompareAndDelete with nil to delete the entry")
+	}
+	if _, ok := cache.Get("nil"); ok {
+		t.Error("key: `nil` should have been deleted, but still exists")
+	}
+}
+
+func TestRetainTop(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(10, WithEvictionLog[int, int](10))
+	for i := range 10 {
+		cache.Put(i, i)
+	}
+
+	if evicted := cache.RetainTop(3); evicted != 7 {
+		t.Errorf("expected 7 entries to be evicted, but got: %d", evicted)
+	}
+	if keys := cache.Hottest(10); !slices.Equal(keys, []int{9, 8, 7}) {
+		t.Errorf("expected the 3 most recently used entries to survive, but got: %v", keys)
+	}
+	if _, _, evictions := cache.Stats(); evictions != 7 {
+		t.Errorf("expected evictions to be 7, but got: %d", evictions)
+	}
+	for _, event := range cache.RecentEvictions() {
+		if event.Reason != ReasonManual {
+			t.Errorf("expected eviction reason to be %s, but got: %s", ReasonManual, event.Reason)
+		}
+	}
+
+	if evicted := cache.RetainTop(5); evicted != 0 {
+		t.Errorf("expected nothing to be evicted when retaining more than the length, but got: %d", evicted)
+	}
+}
+
+func TestStatsStruct(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](3)
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.Get("a")
+	cache.Get("c")
+
+	snapshot := cache.StatsStruct()
+	expected := StatsSnapshot{Hits: 1, Misses: 1, Evictions: 0, Insertions: 2, Length: 2, Capacity: 3}
+	if snapshot != expected {
+		t.Errorf("expected stats to be %+v, but got: %+v", expected, snapshot)
+	}
+
+	cache.Put("c", 3)
+	cache.Put("d", 4)
+	if snapshot := cache.StatsStruct(); snapshot.Length != cache.Len() || snapshot.Evictions != 1 {
+		t.Errorf("expected length %d and 1 eviction, but got: %+v", cache.Len(), snapshot)
+	}
+}
+
+func TestInsertionsAndUpdates(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](2)
+	cache.Put("a", 1)
+	cache.Put("a", 2)
+
+	snapshot := cache.StatsStruct()
+	if snapshot.Insertions != 1 || snapshot.Updates != 1 {
+		t.Errorf("expected 1 insertion and 1 update, but got: %d, %d", snapshot.Insertions, snapshot.Updates)
+	}
+
+	cache.PutPairs([]Pair[string, int]{{"b", 1}, {"c", 1}, {"b", 2}})
+	snapshot = cache.StatsStruct()
+	if snapshot.Insertions != 3 || snapshot.Updates != 2 {
+		t.Errorf("expected 3 insertions and 2 updates, but got: %d, %d", snapshot.Insertions, snapshot.Updates)
+	}
+}
+
+func TestEvictBatch(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(10, WithEvictBatch[int, int](4))
+	for i := range 10 {
+		cache.Put(i, i)
+	}
+
+	// the first insert beyond capacity evicts a whole batch
+	cache.Put(10, 10)
+	if cache.Len() != 7 {
+		t.Errorf("expected cache length to drop to 7, but got: %d", cache.Len())
+	}
+	if keys := cache.Coldest(1); keys[0] != 4 {
+		t.Errorf("expected the 4 least recently used entries to be evicted, but got coldest: %v", keys)
+	}
+
+	// no eviction until capacity is reached again
+	cache.Put(11, 11)
+	cache.Put(12, 12)
+	cache.Put(13, 13)
+	if _, _, evictions := cache.Stats(); evictions != 4 || cache.Len() != 10 {
+		t.Errorf("expected 4 evictions and length 10, but got: %d, %d", evictions, cache.Len())
+	}
+
+	evictedKeys := cache.PutAll(map[int]int{14: 14})
+	if !slices.Equal(evictedKeys, []int{4, 5, 6, 7}) {
+		t.Errorf("expected PutAll to report the batch [4 5 6 7], but got: %v", evictedKeys)
+	}
+}
+
+func TestGetMultiStats(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](5)
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.Put("c", 3)
+	cache.Get("a")
+
+	found, hits, misses := cache.GetMultiStats([]string{"a", "b", "x", "y", "z"})
+	if hits != 2 || misses != 3 {
+		t.Errorf("expected 2 hits and 3 misses, but got: %d, %d", hits, misses)
+	}
+	if expected := map[string]int{"a": 1, "b": 2}; !maps.Equal(found, expected) {
+		t.Errorf("expected found values to be %v, but got: %v", expected, found)
+	}
+	// cumulative stats include the earlier Get
+	if totalHits, totalMisses, _ := cache.Stats(); totalHits != 3 || totalMisses != 3 {
+		t.Errorf("expected 3 total hits and 3 total misses, but got: %d, %d", totalHits, totalMisses)
+	}
+	if keys := cache.Hottest(1); keys[0] != "b" {
+		t.Errorf("expected hits to be promoted, but got hottest: %v", keys)
+	}
+}
+
+func TestReferenceBit(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(3, WithReferenceBit[string, int]())
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.Put("c", 3)
+
+	if value, ok := cache.Get("a"); !ok || value != 1 {
+		t.Errorf("expected key: `a` to exists with value: 1, but got: %d, %t", value, ok)
+	}
+	if keys := cache.Hottest(3); !slices.Equal(keys, []string{"c", "b", "a"}) {
+		t.Errorf("expected Get not to reorder entries, but got: %v", keys)
+	}
+
+	// a is referenced and gets a second chance, so b is evicted instead
+	cache.Put("d", 4)
+	if _, ok := cache.Get("b"); ok {
+		t.Error("key: `b` should have been evicted, but still exists")
+	}
+	if keys := cache.Hottest(3); !slices.Equal(keys, []string{"d", "a", "c"}) {
+		t.Errorf("expected order after eviction to be [d a c], but got: %v", keys)
+	}
+
+	// the second chance cleared a's bit, so it is evicted next time round
+	cache.Put("e", 5)
+	cache.Put("f", 6)
+	if !cache.ContainsAll("d", "e", "f") || cache.ContainsAny("a", "c") {
+		t.Errorf("expected only d, e and f to remain, but got: %v", cache.Hottest(3))
+	}
+}
+
+func TestReferenceBitAllReferenced(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(3, WithReferenceBit[int, int]())
+	for i := range 3 {
+		cache.Put(i, i)
+		cache.Get(i)
+	}
+
+	// every entry is referenced, so the sweep clears all bits and evicts the oldest
+	cache.Put(3, 3)
+	if _, ok := cache.Get(0); ok {
+		t.Error("key: 0 should have been evicted, but still exists")
+	}
+	if cache.Len() != 3 {
+		t.Errorf("expected cache length to be 3, but got: %d", cache.Len())
+	}
+}
+
+func TestMaxKeySize(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(3, WithMaxKeySize[string, int](4, nil))
+
+	if err := cache.PutChecked("abcd", 1); err != nil {
+		t.Errorf("expected key at the limit to be accepted, but got: %v", err)
+	}
+	if err := cache.PutChecked("abcde", 2); !errors.Is(err, ErrKeyTooLarge) {
+		t.Errorf("expected error to be %v, but got: %v", ErrKeyTooLarge, err)
+	}
+	cache.Put("toolong", 3)
+	cache.PutWithPriority("toolong", 3, 1)
+	if cache.Len() != 1 || cache.ContainsAny("abcde", "toolong") {
+		t.Errorf("expected only the normal key to be cached, but got: %v", cache.Hottest(3))
+	}
+
+	// a custom size function limits other key types
+	ints, _ := New(3, WithMaxKeySize[int, int](100, func(key int) int { return key }))
+	if err := ints.PutChecked(101, 1); !errors.Is(err, ErrKeyTooLarge) {
+		t.Errorf("expected error to be %v, but got: %v", ErrKeyTooLarge, err)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](5)
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.Put("c", 3)
+	previous := map[string]int{"a": 1, "b": 2, "c": 3}
+
+	cache.Put("d", 4)
+	cache.Delete("b")
+	cache.Put("c", 30)
+	cache.Put("a", 1)
+
+	added, removed, changed := cache.Diff(previous)
+	if !slices.Equal(added, []string{"d"}) {
+		t.Errorf("expected added keys to be [d], but got: %v", added)
+	}
+	if !slices.Equal(removed, []string{"b"}) {
+		t.Errorf("expected removed keys to be [b], but got: %v", removed)
+	}
+	if !slices.Equal(changed, []string{"c"}) {
+		t.Errorf("expected changed keys to be [c], but got: %v", changed)
+	}
+}
+
+func TestEvictionSampleRate(t *testing.T) {
+	t.Parallel()
+	var samples []EvictionEvent[int]
+	cache, _ := New(5, WithEvictionSampleRate[int, int](3, func(event EvictionEvent[int]) {
+		samples = append(samples, event)
+	}))
+
+	// 15 puts into a capacity of 5 evict keys 0 to 9
+	for i := range 15 {
+		cache.Put(i, i)
+	}
+
+	if len(samples) != 3 {
+		t.Fatalf("expected callback to fire 3 times, but got: %d", len(samples))
+	}
+	for i, sample := range samples {
+		if expected := 3*(i+1) - 1; sample.Key != expected || sample.Reason != ReasonCapacity {
+			t.Errorf("expected sample %d to be key %d evicted for capacity, but got: %+v", i, expected, sample)
+		}
+	}
+}
+
+func TestEvictionSampleRateConcurrency(t *testing.T) {
+	t.Parallel()
+	var fired atomic.Int64
+	cache, _ := New(10, WithEvictionSampleRate[int, int](7, func(EvictionEvent[int]) {
+		fired.Add(1)
+	}))
+
+	var wg sync.WaitGroup
+	for g := range 10 {
+		wg.Go(func() {
+			for i := range 100 {
+				cache.Put(g*100+i, i)
+			}
+		})
+	}
+	wg.Wait()
+
+	// 1000 distinct keys into a capacity of 10 evict 990 entries
+	if expected := int64(990 / 7); fired.Load() != expected {
+		t.Errorf("expected callback to fire %d times, but got: %d", expected, fired.Load())
+	}
+}
+
+func TestOnEvict(t *testing.T) {
+	t.Parallel()
+	var evicted []Pair[string, int]
+	var reasons []EvictionReason
+	var cache *cache[string, int]
+	cache, _ = New(2, WithOnEvict(func(key string, value int, reason EvictionReason) {
+		// the callback runs without the lock, so it may use the cache
+		_ = cache.Len()
+		evicted = append(evicted, Pair[string, int]{key, value})
+		reasons = append(reasons, reason)
+	}))
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.Put("c", 3)
+	cache.RetainTop(1)
+
+	if expected := []Pair[string, int]{{"a", 1}, {"b", 2}}; !slices.Equal(evicted, expected) {
+		t.Errorf("expected evicted entries to be %v, but got: %v", expected, evicted)
+	}
+	if expected := []EvictionReason{ReasonCapacity, ReasonManual}; !slices.Equal(reasons, expected) {
+		t.Errorf("expected eviction reasons to be %v, but got: %v", expected, reasons)
+	}
+
+	evicted = nil
+	cache.Clear()
+	if len(evicted) != 0 {
+		t.Errorf("expected Clear not to call the callback, but got: %v", evicted)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	t.Parallel()
+	evicted := map[string]EvictionReason{}
+	cache, _ := New(5, WithOnEvict(func(key string, value int, reason EvictionReason) {
+		evicted[key] = reason
+	}))
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.Put("c", 3)
+	cache.Get("a")
+
+	cache.Purge()
+
+	if cache.Len() != 0 {
+		t.Errorf("expected cache to be empty, but got length: %d", cache.Len())
+	}
+	if hits, _, _ := cache.Stats(); hits != 0 {
+		t.Errorf("expected stats to be reset, but got hits: %d", hits)
+	}
+	if len(evicted) != 3 {
+		t.Errorf("expected callback for all 3 entries, but got: %v", evicted)
+	}
+	for key, reason := range evicted {
+		if reason != ReasonCleared {
+			t.Errorf("expected key: `%s` to be evicted with %s, but got: %s", key, ReasonCleared, reason)
+		}
+	}
+}
+
+func TestPopIf(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](3)
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+
+	ready := func(key string, value int) bool { return value%2 == 0 }
+	if _, _, ok := cache.PopIf(ready); ok {
+		t.Error("expected no pop when the tail fails the predicate")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("expected cache length to stay 2, but got: %d", cache.Len())
+	}
+
+	cache.Get("a")
+	key, value, ok := cache.PopIf(ready)
+	if !ok || key != "b" || value != 2 {
+		t.Errorf("expected tail b: 2 to be popped, but got: %s: %d, %t", key, value, ok)
+	}
+	if cache.ContainsAny("b") {
+		t.Error("key: `b` should have been removed, but still exists")
+	}
+
+	cache.Clear()
+	if _, _, ok := cache.PopIf(ready); ok {
+		t.Error("expected no pop from an empty cache")
+	}
+}
+
+func TestDeterministicOrdering(t *testing.T) {
+	t.Parallel()
+	build := func() *cache[int, int] {
+		cache, _ := New[int, int](50)
+		for i := range 80 {
+			cache.Put(i*7%101, i)
+			if i%3 == 0 {
+				cache.Get(i * 5 % 101)
+			}
+		}
+		return cache
+	}
+
+	reference := build()
+	hottest, coldest := reference.Hottest(50), reference.Coldest(50)
+	added, _, _ := reference.Diff(nil)
+	drained := reference.Drain()
+	// map iteration order is randomized per map, so fresh caches would expose
+	// any order that depends on it
+	for range 20 {
+		cache := build()
+		if keys := cache.Hottest(50); !slices.Equal(keys, hottest) {
+			t.Fatalf("expected Hottest to be deterministic, but got: %v and %v", hottest, keys)
+		}
+		if keys := cache.Coldest(50); !slices.Equal(keys, coldest) {
+			t.Fatalf("expected Coldest to be deterministic, but got: %v and %v", coldest, keys)
+		}
+		var ranged []int
+		cache.RangeMatch(func(int) bool { return true }, func(key, _ int) bool {
+			ranged = append(ranged, key)
+			return true
+		})
+		if !slices.Equal(ranged, hottest) {
+			t.Fatalf("expected RangeMatch to follow the recency order, but got: %v", ranged)
+		}
+		if keys, _, _ := cache.Diff(nil); !slices.Equal(keys, added) {
+			t.Fatalf("expected Diff to be deterministic, but got: %v and %v", added, keys)
+		}
+		if pairs := cache.Drain(); !slices.Equal(pairs, drained) {
+			t.Fatalf("expected Drain to be deterministic, but got: %v and %v", drained, pairs)
+		}
+	}
+}
+
+func TestWriterNotStarved(t *testing.T) {
+	t.Parallel()
+	// with reference bits every hit takes only the read lock
+	cache, _ := New(100, WithReferenceBit[int, int]())
+	cache.Put(0, 0)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					cache.Get(0)
+				}
+			}
+		})
+	}
+
+	var slowest time.Duration
+	for i := range 100 {
+		start := time.Now()
+		cache.Put(i, i)
+		slowest = max(slowest, time.Since(start))
+	}
+	close(stop)
+	wg.Wait()
+
+	// generous bound, as a starved writer would wait for as long as reads continue
+	if slowest > time.Second {
+		t.Errorf("expected Put latency to stay bounded under reads, but the slowest took: %s", slowest)
+	}
+}
+
+func TestWindowedHitRatio(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(10, WithHitRateWindow[int, int](10))
+	if ratio := cache.WindowedHitRatio(); ratio != 0 {
+		t.Errorf("expected ratio before any lookup to be 0, but got: %f", ratio)
+	}
+	cache.Put(1, 1)
+	cache.Put(2, 2)
+
+	for i := range 20 {
+		cache.Get(1 + i%2)
+	}
+	if ratio := cache.WindowedHitRatio(); ratio != 1 {
+		t.Errorf("expected ratio to be 1 after only hits, but got: %f", ratio)
+	}
+
+	// the hit rate drops: the window only sees the misses
+	for i := range 10 {
+		cache.Get(100 + i)
+	}
+	if ratio := cache.WindowedHitRatio(); ratio != 0 {
+		t.Errorf("expected ratio to be 0 after 10 misses, but got: %f", ratio)
+	}
+
+	for range 5 {
+		cache.Get(1)
+	}
+	if ratio := cache.WindowedHitRatio(); ratio != 0.5 {
+		t.Errorf("expected ratio to be 0.5, but got: %f", ratio)
+	}
+
+	cache.Clear()
+	if ratio := cache.WindowedHitRatio(); ratio != 0 {
+		t.Errorf("expected ratio to be reset by Clear, but got: %f", ratio)
+	}
+}
+
+func TestNearEvictionHook(t *testing.T) {
+	t.Parallel()
+	var rescued []int
+	var cache *cache[int, int]
+	cache, _ = New(5, WithNearEvictionHook[int, int](2, func(key int) {
+		// the hook runs without the lock, so it may use the cache
+		_ = cache.Len()
+		rescued = append(rescued, key)
+	}))
+	for i := range 5 {
+		cache.Put(i, i)
+	}
+
+	// order from the tail: 0 1 2 3 4
+	cache.Get(4)
+	cache.Get(2)
+	cache.Get(0)
+	// order from the tail: 1 3 4 2 0
+	cache.Get(3)
+	cache.Get(-1)
+
+	if !slices.Equal(rescued, []int{0, 3}) {
+		t.Errorf("expected hook to fire for [0 3], but got: %v", rescued)
+	}
+}
+
+type mockSink struct {
+	hits, misses, evictions atomic.Int64
+}
+
+func (s *mockSink) IncHits()      { s.hits.Add(1) }
+func (s *mockSink) IncMisses()    { s.misses.Add(1) }
+func (s *mockSink) IncEvictions() { s.evictions.Add(1) }
+
+func TestStatsSink(t *testing.T) {
+	t.Parallel()
+	sink := &mockSink{}
+	cache, _ := New(2, WithStatsSink[string, int](sink))
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("c")
+	cache.Put("c", 3)
+
+	if sink.hits.Load() != 2 || sink.misses.Load() != 1 || sink.evictions.Load() != 1 {
+		t.Errorf("expected sink to get 2 hits, 1 miss and 1 eviction, but got: %d, %d, %d", sink.hits.Load(), sink.misses.Load(), sink.evictions.Load())
+	}
+	// the internal counters are kept alongside the sink
+	if hits, misses, evictions := cache.Stats(); hits != 2 || misses != 1 || evictions != 1 {
+		t.Errorf("expected stats to be 2, 1, 1, but got: %d, %d, %d", hits, misses, evictions)
+	}
+}
+
+func TestClearAndRelease(t *testing.T) {
+	t.Parallel()
+	for name, clearFn := range map[string]func(*cache[int, int]){
+		"Clear":           (*cache[int, int]).Clear,
+		"ClearAndRelease": (*cache[int, int]).ClearAndRelease,
+	} {
+		cache, _ := New[int, int](3)
+		for i := range 5 {
+			cache.Put(i, i)
+		}
+		clearFn(cache)
+
+		if cache.Len() != 0 {
+			t.Errorf("expected cache to be empty after %s, but got length: %d", name, cache.Len())
+		}
+		for i := range 4 {
+			cache.Put(i, i)
+		}
+		if cache.Len() != 3 || !cache.ContainsAll(1, 2, 3) {
+			t.Errorf("expected cache to be usable after %s, but got: %v", name, cache.Hottest(3))
+		}
+		if err := cache.CheckInvariants(); err != nil {
+			t.Errorf("expected a consistent cache after %s, but got: %v", name, err)
+		}
+	}
+}
+
+func TestElementPool(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(16, WithElementPool[int, int]())
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			for i := range 1000 {
+				key := g*1000 + i
+				cache.Put(key, key)
+				if value, ok := cache.Get(key - 3); ok && value != key-3 {
+					t.Errorf("expected recycled entry of key: %d to hold its own value, but got: %d", key-3, value)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	if err := cache.CheckInvariants(); err != nil {
+		t.Errorf("expected a consistent cache after churn, but got: %v", err)
+	}
+	cache.RangeMatch(func(int) bool { return true }, func(key, value int) bool {
+		if key != value {
+			t.Errorf("expected key: %d to hold its own value, but got: %d", key, value)
+		}
+		return true
+	})
+}
+
+func TestFreeze(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](2)
+	cache.Put(1, 1)
+	cache.Put(2, 2)
+	cache.Freeze()
+
+	if err := cache.PutChecked(3, 3); !errors.Is(err, ErrFrozen) {
+		t.Errorf("expected PutChecked to return ErrFrozen, but got: %v", err)
+	}
+	if val, ok := cache.Get(1); !ok || val != 1 {
+		t.Errorf("expected Get to still serve 1, but got: %d, %t", val, ok)
+	}
+	if oldest := cache.Coldest(1); len(oldest) != 1 || oldest[0] != 1 {
+		t.Errorf("expected Get not to promote key 1 while frozen, but coldest is: %v", oldest)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		cache.Put(3, 3)
+		cache.Delete(2)
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("expected Put to block while the cache is frozen")
+	case <-time.After(20 * time.Millisecond):
+	}
+	if cache.Len() != 2 || !cache.Contains(2) {
+		t.Errorf("expected the frozen cache to be unchanged, but got len: %d", cache.Len())
+	}
+
+	cache.Unfreeze()
+	<-done
+	if cache.Contains(1) || cache.Contains(2) || !cache.Contains(3) {
+		t.Errorf("expected the blocked writes to resume after Unfreeze, but got len: %d", cache.Len())
+	}
+	if err := cache.PutChecked(4, 4); err != nil {
+		t.Errorf("expected PutChecked to succeed after Unfreeze, but got: %v", err)
+	}
+}
+
+func TestReserve(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(100, WithInitialMapSize[int, int](1))
+	for i := range 3 {
+		cache.Put(i, i)
+	}
+	cache.Get(0)
+
+	cache.Reserve(1000)
+	// less than the cached entries is a no-op
+	cache.Reserve(1)
+
+	for i := range 3 {
+		if val, ok := cache.Peek(i); !ok || val != i {
+			t.Errorf("expected key: %d to survive Reserve with value: %d, but got: %d, %t", i, i, val, ok)
+		}
+	}
+	if oldest := cache.Coldest(1); len(oldest) != 1 || oldest[0] != 1 {
+		t.Errorf("expected recency order to be preserved, but coldest is: %v", oldest)
+	}
+	if cache.Capacity() != 100 {
+		t.Errorf("expected capacity to stay 100, but got: %d", cache.Capacity())
+	}
+	for i := 3; i < 150; i++ {
+		cache.Put(i, i)
+	}
+	if cache.Len() != 100 {
+		t.Errorf("expected cache length to be 100, but got: %d", cache.Len())
+	}
+	if err := cache.CheckInvariants(); err != nil {
+		t.Errorf("expected no invariant violations, but got: %v", err)
+	}
+}
+
+func TestEvictionVeto(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(3, WithEvictionVeto(func(key string, _ int) bool {
+		return key == "pinned"
+	}))
+	cache.Put("pinned", 1)
+	cache.Put("b", 2)
+	cache.Put("c", 3)
+
+	cache.Put("d", 4)
+	if !cache.Contains("pinned") {
+		t.Error("expected the vetoed key to be kept")
+	}
+	if cache.Contains("b") {
+		t.Error("expected the next coldest key to be evicted instead")
+	}
+
+	// with every entry vetoed the least recently used one is evicted anyway
+	all, _ := New(2, WithEvictionVeto(func(string, int) bool { return true }))
+	all.Put("a", 1)
+	all.Put("b", 2)
+	all.Put("c", 3)
+	if all.Contains("a") || all.Len() != 2 {
+		t.Errorf("expected the vetoed tail to be forced out, but got len: %d", all.Len())
+	}
+}
+
+func TestCopyTo(t *testing.T) {
+	t.Parallel()
+	src, _ := New[int, int](5)
+	for i := range 5 {
+		src.Put(i, i*10)
+	}
+	src.Get(0)
+	hits, _, _ := src.Stats()
+
+	dst, _ := New[int, int](3)
+	src.CopyTo(dst)
+
+	if dst.Len() != 3 {
+		t.Fatalf("expected destination length to be 3, but got: %d", dst.Len())
+	}
+	// the three most recently used entries, in the same order
+	if hottest := dst.Hottest(3); !slices.Equal(hottest, []int{0, 4, 3}) {
+		t.Errorf("expected destination to hold [0 4 3], but got: %v", hottest)
+	}
+	if val, ok := dst.Peek(4); !ok || val != 40 {
+		t.Errorf("expected value to be 40, but got: %d, %t", val, ok)
+	}
+	if src.Len() != 5 || !slices.Equal(src.Hottest(5), []int{0, 4, 3, 2, 1}) {
+		t.Errorf("expected the source to be unchanged, but got: %v", src.Hottest(5))
+	}
+	if after, _, _ := src.Stats(); after != hits {
+		t.Errorf("expected source hits to stay %d, but got: %d", hits, after)
+	}
+}
+
+func TestSwap(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](2)
+
+	if old, loaded := cache.Swap("a", 1); loaded || old != 0 {
+		t.Errorf("expected no previous value, but got: %d, %t", old, loaded)
+	}
+	cache.Put("b", 2)
+	if old, loaded := cache.Swap("a", 10); !loaded || old != 1 {
+		t.Errorf("expected previous value to be 1, but got: %d, %t", old, loaded)
+	}
+	if val, ok := cache.Peek("a"); !ok || val != 10 {
+		t.Errorf("expected value to be 10, but got: %d, %t", val, ok)
+	}
+
+	// "a" was promoted by the swap, so inserting "c" evicts "b"
+	cache.Swap("c", 3)
+	if cache.Len() != 2 || cache.Contains("b") || !cache.Contains("a") {
+		t.Errorf("expected b to be evicted, but got: %v", cache.Hottest(2))
+	}
+}
+
+func TestRangeLimit(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](10)
+	for i := range 5 {
+		cache.Put(i, i*10)
+	}
+
+	for _, limit := range []int{0, 3, 5, 8} {
+		var keys []int
+		cache.RangeLimit(limit, func(key, value int) bool {
+			if value != key*10 {
+				t.Errorf("expected value to be %d, but got: %d", key*10, value)
+			}
+			keys = append(keys, key)
+			return true
+		})
+		want := []int{4, 3, 2, 1, 0}[:min(limit, cache.Len())]
+		if !slices.Equal(keys, want) {
+			t.Errorf("expected a limit of %d to visit %v, but got: %v", limit, want, keys)
+		}
+	}
+
+	visited := 0
+	cache.RangeLimit(5, func(int, int) bool {
+		visited++
+		return visited < 2
+	})
+	if visited != 2 {
+		t.Errorf("expected the walk to stop when fn returns false, but visited: %d", visited)
+	}
+}
+
+func TestGetPromoted(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](3)
+	for i := range 3 {
+		cache.Put(i, i)
+	}
+
+	if val, ok, promoted := cache.GetPromoted(0); !ok || val != 0 || !promoted {
+		t.Errorf("expected the cold key to be promoted, but got: %d, %t, %t", val, ok, promoted)
+	}
+	if _, ok, promoted := cache.GetPromoted(0); !ok || promoted {
+		t.Errorf("expected a repeated access to the front key not to promote, but got: %t, %t", ok, promoted)
+	}
+	if _, ok, promoted := cache.GetPromoted(5); ok || promoted {
+		t.Errorf("expected a miss not to promote, but got: %t, %t", ok, promoted)
+	}
+
+	referenced, _ := New(3, WithReferenceBit[int, int]())
+	referenced.Put(0, 0)
+	referenced.Put(1, 1)
+	if _, ok, promoted := referenced.GetPromoted(0); !ok || promoted {
+		t.Errorf("expected no promotion with WithReferenceBit, but got: %t, %t", ok, promoted)
+	}
+}
+
+func TestGhostTTL(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(1, WithGhostList[string, int](10), WithGhostTTL[string, int](time.Minute))
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+
+	cache.Put("a", 1)
+	cache.Put("b", 2) // evicts "a" into the ghost list
+	now = now.Add(30 * time.Second)
+	cache.Put("c", 3) // evicts "b" into the ghost list
+
+	now = now.Add(45 * time.Second)
+	cache.Get("a") // evicted 75s ago, forgotten
+	cache.Get("b") // evicted 45s ago, ghost hit
+	if ghostHits := cache.GhostHits(); ghostHits != 1 {
+		t.Errorf("expected ghost hits to be 1, but got: %d", ghostHits)
+	}
+	if n := cache.ghosts.orderList.Len(); n != 0 {
+		t.Errorf("expected the ghost list to be empty, but got: %d", n)
+	}
+
+	// expired keys are pruned by the next ghost list access
+	cache.Put("d", 4) // evicts "c" into the ghost list
+	cache.Put("e", 5) // evicts "d" into the ghost list
+	now = now.Add(time.Minute)
+	cache.Get("z")
+	if n := len(cache.ghosts.m); n != 0 {
+		t.Errorf("expected the expired ghost keys to be pruned, but got: %d", n)
+	}
+}
+
+func TestDeleteMulti(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](10)
+	for i := range 5 {
+		cache.Put(i, i)
+	}
+
+	if n := cache.DeleteMulti(1, 3, 7, 3, 9); n != 2 {
+		t.Errorf("expected 2 keys to be deleted, but got: %d", n)
+	}
+	if cache.Len() != 3 || cache.Contains(1) || cache.Contains(3) {
+		t.Errorf("expected keys 1 and 3 to be deleted, but got: %v", cache.Hottest(5))
+	}
+	if n := cache.DeleteMulti(); n != 0 {
+		t.Errorf("expected no keys to be deleted, but got: %d", n)
+	}
+}
+
+func TestOrderIter(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](5)
+	for i := range 5 {
+		cache.Put(i, i)
+	}
+	cache.Get(2)
+
+	// Get and Put in the loop body would deadlock if the lock were held
+	var keys []int
+	for key := range cache.OrderIter() {
+		keys = append(keys, key)
+		cache.Get(key)
+		cache.Put(key+10, key)
+	}
+	if !slices.Equal(keys, []int{2, 4, 3, 1, 0}) {
+		t.Errorf("expected keys in recency order [2 4 3 1 0], but got: %v", keys)
+	}
+	if first := slices.Collect(cache.OrderIter())[0]; first != 10 {
+		t.Errorf("expected the last put key to be the most recent, but got: %d", first)
+	}
+
+	visited := 0
+	for range cache.OrderIter() {
+		visited++
+		break
+	}
+	if visited != 1 {
+		t.Errorf("expected the loop to stop after one key, but visited: %d", visited)
+	}
+}
+
+func TestEvictionsPerSecond(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](10)
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+	// restarts sampling on the fake clock
+	cache.Clear()
+
+	for i := range 110 {
+		cache.Put(i, i)
+	}
+	now = now.Add(10 * time.Second)
+	if rate := cache.EvictionsPerSecond(); rate != 10 {
+		t.Errorf("expected the burst to evict 10 per second, but got: %f", rate)
+	}
+
+	// a quiet window brings the rate down
+	now = now.Add(10 * time.Second)
+	if rate := cache.EvictionsPerSecond(); rate != 0 {
+		t.Errorf("expected no recent evictions, but got: %f", rate)
+	}
+
+	for i := range 50 {
+		cache.Put(1000+i, i)
+	}
+	now = now.Add(5 * time.Second)
+	if rate := cache.EvictionsPerSecond(); rate != 50.0/15 {
+		t.Errorf("expected a rate of %f, but got: %f", 50.0/15, rate)
+	}
+}
+
+func TestPeakLen(t *testing.T) {
+	t.Parallel()
+	cache := NewUnbounded[int, int]()
+	for i := range 8 {
+		cache.Put(i, i)
+	}
+	cache.DeleteMulti(0, 1, 2, 3, 4)
+	cache.Put(10, 10)
+	cache.Put(10, 11)
+
+	if cache.Len() != 4 {
+		t.Errorf("expected cache length to be 4, but got: %d", cache.Len())
+	}
+	if peak := cache.PeakLen(); peak != 8 {
+		t.Errorf("expected peak length to be 8, but got: %d", peak)
+	}
+
+	// a full bounded cache peaks at its capacity
+	bounded, _ := New[int, int](3)
+	for i := range 10 {
+		bounded.Put(i, i)
+	}
+	if peak := bounded.PeakLen(); peak != 3 {
+		t.Errorf("expected peak length to be 3, but got: %d", peak)
+	}
+
+	cache.Clear()
+	if peak := cache.PeakLen(); peak != 0 {
+		t.Errorf("expected Clear to reset the peak length, but got: %d", peak)
+	}
+}
+
 func BenchmarkPut(b *testing.B) {
 	cache, _ := New[int, int](1000)
 
@@ -247,3 +2169,122 @@ func BenchmarkGet(b *testing.B) {
 		i = i % 1000
 	}
 }
+
+func BenchmarkGetFrontHot(b *testing.B) {
+	cache, _ := New[int, string](1000)
+
+	for i := range 1000 {
+		cache.Put(i, "value")
+	}
+	cache.Get(0)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			cache.Get(0)
+		}
+	})
+}
+
+func BenchmarkPutEvictBatch(b *testing.B) {
+	cache, _ := New(1000, WithEvictBatch[int, int](64))
+
+	i := 0
+	for b.Loop() {
+		cache.Put(i, i)
+		i++
+	}
+}
+
+func benchmarkGetParallel(b *testing.B, opts ...Option[int, string]) {
+	cache, _ := New(1000, opts...)
+
+	for i := range 1000 {
+		cache.Put(i, "value")
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			cache.Get(i)
+			i = (i + 7) % 1000
+		}
+	})
+}
+
+func BenchmarkGetParallel(b *testing.B) {
+	benchmarkGetParallel(b)
+}
+
+func BenchmarkGetParallelReferenceBit(b *testing.B) {
+	benchmarkGetParallel(b, WithReferenceBit[int, string]())
+}
+
+func BenchmarkPutElementPool(b *testing.B) {
+	cache, _ := New(1000, WithElementPool[int, int]())
+
+	b.ReportAllocs()
+	i := 0
+	for b.Loop() {
+		cache.Put(i, i)
+		i++
+	}
+}
+
+// BenchmarkGetOrComputeParallel loads twice as many keys as fit, so that a
+// large share of the calls miss and compute
+func BenchmarkGetOrComputeParallel(b *testing.B) {
+	const capacity = 1024
+	cache, _ := New[int, int](capacity)
+	var seed atomic.Int64
+	b.RunParallel(func(pb *testing.PB) {
+		key := int(seed.Add(7919))
+		for pb.Next() {
+			key = (key*31 + 7) % (2 * capacity)
+			cache.GetOrCompute(key, func() (int, error) {
+				return key, nil
+			})
+		}
+	})
+}
+
+// BenchmarkGetOrComputeHits compares read-heavy GetOrCompute hits served under
+// the read lock with the same hits forced onto the write lock, which
+// WithNearEvictionHook does because only the write lock tells the distance of
+// an entry to the tail. "front" hits the most recently used entry only,
+// "reference-bit" spreads the hits over every entry with WithReferenceBit.
+func BenchmarkGetOrComputeHits(b *testing.B) {
+	const capacity = 1024
+	forceWriteLock := WithNearEvictionHook[int, int](1, func(int) {})
+	cases := []struct {
+		name string
+		keys int
+		opts []Option[int, int]
+	}{
+		{"front/read-lock", 1, nil},
+		{"front/write-lock", 1, []Option[int, int]{forceWriteLock}},
+		{"reference-bit/read-lock", capacity, []Option[int, int]{WithReferenceBit[int, int]()}},
+		{"reference-bit/write-lock", capacity, []Option[int, int]{WithReferenceBit[int, int](), forceWriteLock}},
+	}
+	for _, bc := range cases {
+		b.Run(bc.name, func(b *testing.B) {
+			cache, _ := New(capacity, bc.opts...)
+			for key := range capacity {
+				cache.Put(key, key)
+			}
+			cache.Get(0)
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				key := 0
+				for pb.Next() {
+					cache.GetOrCompute(key, func() (int, error) {
+						return key, nil
+					})
+					key = (key + 7) % bc.keys
+				}
+			})
+		})
+	}
+}
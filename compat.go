@@ -0,0 +1,58 @@
+package lrucache
+
+// The methods in this file mirror the API of hashicorp/golang-lru so that
+// code written against it can switch with minimal changes.
+
+// Add stores the value like Put and reports whether an entry was evicted to
+// make room for it.
+func (c *cache[K, V]) Add(key K, value V) (evicted bool) {
+	c.lockWritable()
+	defer c.unlock()
+
+	var evictedKeys []K
+	c.put(key, value, &evictedKeys)
+	return len(evictedKeys) > 0
+}
+
+// Remove deletes key like Delete and reports whether it was present.
+func (c *cache[K, V]) Remove(key K) (present bool) {
+	c.lockWritable()
+	defer c.lock.Unlock()
+
+	element, ok := c.m[key]
+	if !ok {
+		return false
+	}
+	c.remove(element)
+	return true
+}
+
+// Contains reports whether key is cached without changing recency or stats.
+func (c *cache[K, V]) Contains(key K) bool {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	_, ok := c.m[key]
+	return ok
+}
+
+// Peek returns the value for key without changing recency or stats.
+func (c *cache[K, V]) Peek(key K) (value V, ok bool) {
+	return c.peek(key)
+}
+
+// RemoveOldest evicts the least recently used entry and returns it. The
+// eviction is recorded with ReasonManual. ok is false if the cache is empty.
+func (c *cache[K, V]) RemoveOldest() (key K, value V, ok bool) {
+	c.lockWritable()
+	defer c.unlock()
+
+	element := c.orderList.Back()
+	if element == nil {
+		return key, value, false
+	}
+	// read before evicting, as the entry may be recycled
+	key, value = element.key, element.value
+	c.evictElement(element, ReasonManual)
+	return key, value, true
+}
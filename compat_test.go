@@ -0,0 +1,72 @@
+package lrucache
+
+import "testing"
+
+func TestCompatAdd(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](2)
+
+	if cache.Add(1, 1) || cache.Add(2, 2) {
+		t.Error("expected no eviction while below capacity")
+	}
+	if cache.Add(1, 10) {
+		t.Error("expected no eviction when updating an existing key")
+	}
+	if !cache.Add(3, 3) {
+		t.Error("expected an eviction when adding beyond capacity")
+	}
+	if cache.Contains(2) {
+		t.Error("key: 2 should have been evicted, but still exists")
+	}
+}
+
+func TestCompatRemoveContainsPeek(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](2)
+	cache.Add(1, 1)
+	cache.Add(2, 2)
+
+	if value, ok := cache.Peek(1); !ok || value != 1 {
+		t.Errorf("expected key: 1 to exists with value: 1, but got: %d, %t", value, ok)
+	}
+	// Peek does not promote, so 1 is still the oldest
+	cache.Add(3, 3)
+	if cache.Contains(1) {
+		t.Error("key: 1 should have been evicted after Peek, but still exists")
+	}
+	if hits, misses, _ := cache.Stats(); hits != 0 || misses != 0 {
+		t.Errorf("expected Peek and Contains not to count stats, but got: %d hits, %d misses", hits, misses)
+	}
+
+	if !cache.Remove(2) {
+		t.Error("expected Remove of a present key to report true")
+	}
+	if cache.Remove(2) {
+		t.Error("expected Remove of an absent key to report false")
+	}
+	if _, ok := cache.Peek(2); ok {
+		t.Error("expected Peek of a removed key to miss")
+	}
+}
+
+func TestCompatRemoveOldest(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](3)
+	cache.Add(1, 1)
+	cache.Add(2, 2)
+	cache.Add(3, 3)
+	cache.Get(1)
+
+	key, value, ok := cache.RemoveOldest()
+	if !ok || key != 2 || value != 2 {
+		t.Errorf("expected oldest entry 2: 2, but got: %d: %d, %t", key, value, ok)
+	}
+	if cache.Len() != 2 {
+		t.Errorf("expected cache length to be 2, but got: %d", cache.Len())
+	}
+
+	cache.Clear()
+	if _, _, ok := cache.RemoveOldest(); ok {
+		t.Error("expected RemoveOldest on an empty cache to report false")
+	}
+}
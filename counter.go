@@ -0,0 +1,48 @@
+package lrucache
+
+// counterCache is a cache of int64 counters that can be incremented in place
+type counterCache[K comparable] struct {
+	*cache[K, int64]
+}
+
+// Increment adds delta to the counter for key and returns the new value. It
+// reports false, leaving the cache unchanged, if key is absent.
+func (c *counterCache[K]) Increment(key K, delta int64) (int64, bool) {
+	c.lockWritable()
+	defer c.lock.Unlock()
+
+	if _, ok := c.m[key]; !ok {
+		return 0, false
+	}
+	return c.add(key, delta), true
+}
+
+// IncrementOrInit adds delta to the counter for key, first inserting it with
+// value 0 if it is absent, and returns the new value.
+func (c *counterCache[K]) IncrementOrInit(key K, delta int64) int64 {
+	c.lockWritable()
+	defer c.unlock()
+
+	return c.add(key, delta)
+}
+
+// add must be called with the write lock held
+func (c *counterCache[K]) add(key K, delta int64) int64 {
+	var value int64
+	if element, ok := c.m[key]; ok {
+		value = element.value
+	}
+	value += delta
+	c.put(key, value, nil)
+	return value
+}
+
+// NewCounterCache creates a cache of int64 counters. Besides the regular cache
+// methods it supports atomic Increment and IncrementOrInit.
+func NewCounterCache[K comparable](capacity uint, opts ...Option[K, int64]) (*counterCache[K], error) {
+	c, err := New(capacity, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &counterCache[K]{cache: c}, nil
+}
@@ -0,0 +1,53 @@
+package lrucache
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestCounterCacheIncrement(t *testing.T) {
+	t.Parallel()
+	_, err := NewCounterCache[string](0)
+	if err == nil {
+		t.Error("NewCounterCache should return error when capacity is 0")
+	}
+
+	cache, _ := NewCounterCache[string](2)
+	if _, ok := cache.Increment("key", 1); ok {
+		t.Error("Increment should report false for absent key")
+	}
+	if cache.Len() != 0 {
+		t.Errorf("Increment should not insert absent key, but cache length is: %d", cache.Len())
+	}
+
+	if val := cache.IncrementOrInit("key", 5); val != 5 {
+		t.Errorf("expected value to be 5, but got: %d", val)
+	}
+	val, ok := cache.Increment("key", -2)
+	if !ok || val != 3 {
+		t.Errorf("expected value to be 3, but got: %d, %t", val, ok)
+	}
+	if val, _ := cache.Get("key"); val != 3 {
+		t.Errorf("expected cached value to be 3, but got: %d", val)
+	}
+}
+
+func TestCounterCacheConcurrentIncrement(t *testing.T) {
+	t.Parallel()
+	cache, _ := NewCounterCache[string](10)
+	cache.Put("hits", 0)
+
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Go(func() {
+			for range 100 {
+				cache.Increment("hits", 1)
+			}
+		})
+	}
+	wg.Wait()
+
+	if val, _ := cache.Get("hits"); val != 10000 {
+		t.Errorf("expected value to be 10000, but got: %d", val)
+	}
+}
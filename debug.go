@@ -0,0 +1,102 @@
+package lrucache
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// MemReport describes the internal structures of a cache, as returned by
+// DebugMemStats.
+type MemReport struct {
+	// ListElements is the number of elements in the recency list
+	ListElements int
+	// MapEntries is the number of entries in the key map
+	MapEntries int
+	// MapBuckets is an estimate of the 8-slot groups backing the key map
+	MapBuckets int
+	// ValueBytes is the total size of the stored values. Values are measured
+	// shallowly, except that the contents of string and []byte values are
+	// counted as well.
+	ValueBytes uintptr
+	// Mismatch reports that the list and the map do not hold the same number
+	// of entries, which a healthy cache never does
+	Mismatch bool
+}
+
+// DebugMemStats walks the cache under the read lock and reports the sizes of
+// its internal structures. It is a diagnostic aid for tracking down leaks and
+// is O(n) in the number of entries.
+func (c *cache[K, V]) DebugMemStats() MemReport {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	report := MemReport{MapEntries: len(c.m)}
+	for element := c.orderList.Front(); element != nil; element = element.Next() {
+		report.ListElements++
+		report.ValueBytes += valueSize(element.value)
+	}
+	// groups are kept at most 7/8 full
+	report.MapBuckets = (report.MapEntries*8/7 + 7) / 8
+	report.Mismatch = report.ListElements != report.MapEntries
+	return report
+}
+
+func valueSize[V any](value V) uintptr {
+	size := unsafe.Sizeof(value)
+	switch v := any(value).(type) {
+	case string:
+		size += uintptr(len(v))
+	case []byte:
+		size += uintptr(len(v))
+	}
+	return size
+}
+
+// Integrity returns the lengths of the recency list and of the key map,
+// which are always equal in a healthy cache. It is O(1), so unlike
+// CheckInvariants it is cheap enough to export as a production metric.
+func (c *cache[K, V]) Integrity() (listLen int, mapLen int) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.orderList.Len(), len(c.m)
+}
+
+// CheckInvariants verifies under the read lock that the key map and the
+// recency list describe the same entries: equal lengths, consistent links
+// between list elements, every map entry pointing to a list element holding
+// its key, and no key listed twice. It
+// returns an error describing the first violation found, or nil. Like
+// DebugMemStats it is O(n) and meant for tests and staging.
+func (c *cache[K, V]) CheckInvariants() error {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	if c.orderList.Len() != len(c.m) {
+		return fmt.Errorf("list has %d elements but map has %d entries", c.orderList.Len(), len(c.m))
+	}
+
+	// walking the list rather than the map keeps the reported violation
+	// deterministic. With equal lengths and distinct listed keys each mapping
+	// to their own element, the map cannot hold any other entry.
+	seen := make(map[K]struct{}, c.orderList.Len())
+	for element := c.orderList.Front(); element != nil; element = element.Next() {
+		// bounds the walk should the links form a cycle
+		if len(seen) == c.orderList.Len() {
+			return fmt.Errorf("list holds more than its length of %d elements", c.orderList.Len())
+		}
+		if next := element.Next(); next != nil && next.Prev() != element {
+			return fmt.Errorf("key %v is not linked back from the next element", element.key)
+		}
+		if _, ok := seen[element.key]; ok {
+			return fmt.Errorf("key %v is listed more than once", element.key)
+		}
+		seen[element.key] = struct{}{}
+		if c.m[element.key] != element {
+			return fmt.Errorf("key %v does not map to its list element", element.key)
+		}
+	}
+	if len(seen) != c.orderList.Len() {
+		return fmt.Errorf("list holds %d elements but has length %d", len(seen), c.orderList.Len())
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package lrucache
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestDebugMemStats(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, string](10)
+	for i := range 5 {
+		cache.Put(i, "abcd")
+	}
+
+	report := cache.DebugMemStats()
+	if report.ListElements != cache.Len() || report.MapEntries != cache.Len() {
+		t.Errorf("expected list elements and map entries to be %d, but got: %d, %d", cache.Len(), report.ListElements, report.MapEntries)
+	}
+	if report.Mismatch {
+		t.Error("expected no mismatch on a healthy cache")
+	}
+	// string headers plus 4 bytes of contents each
+	if expected := 5 * (unsafe.Sizeof("") + 4); report.ValueBytes != expected {
+		t.Errorf("expected value bytes to be %d, but got: %d", expected, report.ValueBytes)
+	}
+	if report.MapBuckets < 1 {
+		t.Errorf("expected at least 1 map bucket, but got: %d", report.MapBuckets)
+	}
+}
+
+func TestDebugMemStatsMismatch(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](10)
+	cache.Put(1, 1)
+	cache.Put(2, 2)
+
+	// drop the key from the map only, leaving its list element behind
+	delete(cache.m, 1)
+
+	report := cache.DebugMemStats()
+	if !report.Mismatch {
+		t.Error("expected the mismatch to be reported")
+	}
+	if report.ListElements != 2 || report.MapEntries != 1 {
+		t.Errorf("expected 2 list elements and 1 map entry, but got: %d, %d", report.ListElements, report.MapEntries)
+	}
+}
+
+func TestCheckInvariants(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](3)
+	for i := range 5 {
+		cache.Put(i, i)
+	}
+	cache.Delete(3)
+	cache.Get(2)
+
+	if err := cache.CheckInvariants(); err != nil {
+		t.Errorf("expected a healthy cache to pass, but got: %v", err)
+	}
+}
+
+func TestCheckInvariantsCorrupted(t *testing.T) {
+	t.Parallel()
+	corruptions := map[string]func(c *cache[int, int]){
+		"missing map entry": func(c *cache[int, int]) {
+			delete(c.m, 1)
+		},
+		"swapped elements": func(c *cache[int, int]) {
+			c.m[1], c.m[2] = c.m[2], c.m[1]
+		},
+		"element outside the list": func(c *cache[int, int]) {
+			c.m[1] = &container[int, int]{key: 1}
+		},
+		"duplicate key": func(c *cache[int, int]) {
+			c.orderList.Front().key = 1
+		},
+		"broken back link": func(c *cache[int, int]) {
+			c.orderList.Back().prev = nil
+		},
+		"cycle": func(c *cache[int, int]) {
+			c.orderList.Back().next = c.orderList.Front()
+		},
+	}
+
+	for name, corrupt := range corruptions {
+		cache, _ := New[int, int](3)
+		cache.Put(1, 1)
+		cache.Put(2, 2)
+		corrupt(cache)
+
+		if err := cache.CheckInvariants(); err == nil {
+			t.Errorf("expected %s to be detected, but got no error", name)
+		}
+	}
+}
+
+func TestIntegrity(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](4)
+	for i := range 10 {
+		cache.Put(i, i)
+		cache.Get(i - 2)
+		if i%3 == 0 {
+			cache.Delete(i - 1)
+		}
+	}
+
+	listLen, mapLen := cache.Integrity()
+	if listLen != mapLen {
+		t.Errorf("expected list and map lengths to be equal, but got: %d, %d", listLen, mapLen)
+	}
+	if mapLen != cache.Len() {
+		t.Errorf("expected map length to be %d, but got: %d", cache.Len(), mapLen)
+	}
+}
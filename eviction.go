@@ -0,0 +1,128 @@
+package lrucache
+
+import (
+	"sync"
+	"time"
+)
+
+// EvictionReason describes why an entry was removed from the cache.
+type EvictionReason int
+
+const (
+	// ReasonCapacity means the entry was evicted to make room for a new one.
+	ReasonCapacity EvictionReason = iota
+	// ReasonManual means the entry was evicted by an explicit trim such as
+	// RetainTop.
+	ReasonManual
+	// ReasonCleared means the entry was removed by Purge.
+	ReasonCleared
+)
+
+func (r EvictionReason) String() string {
+	switch r {
+	case ReasonCapacity:
+		return "capacity"
+	case ReasonManual:
+		return "manual"
+	case ReasonCleared:
+		return "cleared"
+	default:
+		return "unknown"
+	}
+}
+
+// EvictionEvent records a single eviction.
+type EvictionEvent[K comparable] struct {
+	Key    K
+	Reason EvictionReason
+	Time   time.Time
+}
+
+// evictedEntry is a removed entry waiting to be passed to the WithOnEvict
+// callback
+type evictedEntry[K comparable, V any] struct {
+	key    K
+	value  V
+	reason EvictionReason
+}
+
+// evictionLog is a fixed size ring buffer of the most recent evictions
+type evictionLog[K comparable] struct {
+	events []EvictionEvent[K]
+	next   int
+	full   bool
+}
+
+func newEvictionLog[K comparable](size int) *evictionLog[K] {
+	return &evictionLog[K]{events: make([]EvictionEvent[K], size)}
+}
+
+func (l *evictionLog[K]) add(event EvictionEvent[K]) {
+	l.events[l.next] = event
+	l.next = (l.next + 1) % len(l.events)
+	if l.next == 0 {
+		l.full = true
+	}
+}
+
+// snapshot returns a copy of the logged events, oldest first
+func (l *evictionLog[K]) snapshot() []EvictionEvent[K] {
+	if !l.full {
+		return append([]EvictionEvent[K](nil), l.events[:l.next]...)
+	}
+	events := make([]EvictionEvent[K], 0, len(l.events))
+	events = append(events, l.events[l.next:]...)
+	return append(events, l.events[:l.next]...)
+}
+
+// evictionRateWindow is the minimum age of the sample that EvictionsPerSecond
+// measures from
+const evictionRateWindow = 10 * time.Second
+
+// evictionSample is the evictions counter at a point in time
+type evictionSample struct {
+	at        time.Time
+	evictions uint64
+}
+
+// evictionRate keeps the two latest samples of the evictions counter taken by
+// EvictionsPerSecond, at most one per evictionRateWindow
+type evictionRate struct {
+	lock       sync.Mutex
+	prev, last evictionSample
+}
+
+// reset restarts sampling at now with the counter at zero
+func (r *evictionRate) reset(now time.Time) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.prev = evictionSample{at: now}
+	r.last = r.prev
+}
+
+// rate samples the counter and returns the evictions per second since the
+// previous sample, which covers between one and two windows once sampled
+// regularly
+func (r *evictionRate) rate(now time.Time, evictions uint64) float64 {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if now.Sub(r.last.at) >= evictionRateWindow {
+		r.prev, r.last = r.last, evictionSample{at: now, evictions: evictions}
+	}
+	elapsed := now.Sub(r.prev.at).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(evictions-r.prev.evictions) / elapsed
+}
+
+// EvictionsPerSecond returns the recent eviction rate. It is computed on
+// demand from the evictions counter and a sample of it taken by an earlier
+// call, at least 10 seconds old and renewed every 10 seconds, so when called
+// regularly it reflects the last 10 to 20 seconds. Before the first sample
+// matures it covers the time since the cache was created or last cleared. A
+// sustained high rate means the cache is too small for its working set.
+func (c *cache[K, V]) EvictionsPerSecond() float64 {
+	return c.evictionRate.rate(c.now(), c.stats.evictions.Load())
+}
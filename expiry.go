@@ -0,0 +1,92 @@
+package lrucache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// expiring holds values that expire a fixed ttl after they were set, for the
+// defaults of WithCacheDefaults and the errors of WithErrorCaching. Setting a
+// key moves it to the front, so the entries expire from the back and pruning
+// only visits expired ones, like in the ghost list. It has its own lock, which
+// is never held while taking another.
+type expiring[K comparable, T any] struct {
+	ttl time.Duration
+
+	lock      sync.Mutex
+	orderList *list.List
+	m         map[K]*list.Element
+}
+
+type expiringEntry[K comparable, T any] struct {
+	key     K
+	value   T
+	expires time.Time
+}
+
+func newExpiring[K comparable, T any](ttl time.Duration) *expiring[K, T] {
+	return &expiring[K, T]{
+		ttl:       ttl,
+		orderList: list.New(),
+		m:         make(map[K]*list.Element),
+	}
+}
+
+// get returns the value set for key unless it has expired by now
+func (e *expiring[K, T]) get(key K, now time.Time) (T, bool) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.prune(now)
+	element, ok := e.m[key]
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	return element.Value.(expiringEntry[K, T]).value, true
+}
+
+// set stores value for key until ttl after now
+func (e *expiring[K, T]) set(key K, value T, now time.Time) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.prune(now)
+	entry := expiringEntry[K, T]{key: key, value: value, expires: now.Add(e.ttl)}
+	if element, ok := e.m[key]; ok {
+		element.Value = entry
+		e.orderList.MoveToFront(element)
+		return
+	}
+	e.m[key] = e.orderList.PushFront(entry)
+}
+
+func (e *expiring[K, T]) delete(key K) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if element, ok := e.m[key]; ok {
+		delete(e.m, key)
+		e.orderList.Remove(element)
+	}
+}
+
+// prune drops the entries expired by now, which are all at the back. The lock
+// must be held.
+func (e *expiring[K, T]) prune(now time.Time) {
+	for oldest := e.orderList.Back(); oldest != nil; oldest = e.orderList.Back() {
+		entry := oldest.Value.(expiringEntry[K, T])
+		if now.Before(entry.expires) {
+			return
+		}
+		delete(e.m, entry.key)
+		e.orderList.Remove(oldest)
+	}
+}
+
+func (e *expiring[K, T]) clear() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	clear(e.m)
+	e.orderList.Init()
+}
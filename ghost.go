@@ -0,0 +1,85 @@
+package lrucache
+
+import (
+	"container/list"
+	"time"
+)
+
+// ghostList remembers the keys of recently evicted entries, without their
+// values, so that misses on them can be counted as would-be hits
+type ghostList[K comparable] struct {
+	size      int
+	orderList *list.List
+	m         map[K]*list.Element
+
+	// set by WithGhostTTL, keys are forgotten ttl after their eviction
+	ttl time.Duration
+	now func() time.Time
+}
+
+type ghostEntry[K comparable] struct {
+	key K
+	// when the key was added, only set with a ttl
+	added time.Time
+}
+
+func newGhostList[K comparable](size int) *ghostList[K] {
+	return &ghostList[K]{
+		size:      size,
+		orderList: list.New(),
+		m:         make(map[K]*list.Element, size),
+	}
+}
+
+func (g *ghostList[K]) add(key K) {
+	g.prune()
+	entry := ghostEntry[K]{key: key}
+	if g.ttl > 0 {
+		entry.added = g.now()
+	}
+	if element, ok := g.m[key]; ok {
+		element.Value = entry
+		g.orderList.MoveToFront(element)
+		return
+	}
+	if len(g.m) == g.size {
+		g.removeElement(g.orderList.Back())
+	}
+	g.m[key] = g.orderList.PushFront(entry)
+}
+
+// remove drops key from the list, reporting whether it was present
+func (g *ghostList[K]) remove(key K) bool {
+	g.prune()
+	element, ok := g.m[key]
+	if !ok {
+		return false
+	}
+	g.removeElement(element)
+	return true
+}
+
+func (g *ghostList[K]) removeElement(element *list.Element) {
+	delete(g.m, element.Value.(ghostEntry[K]).key)
+	g.orderList.Remove(element)
+}
+
+// prune forgets the keys added more than ttl ago. Adding moves a key to the
+// front, so they are all at the back.
+func (g *ghostList[K]) prune() {
+	if g.ttl <= 0 {
+		return
+	}
+	expired := g.now().Add(-g.ttl)
+	for oldest := g.orderList.Back(); oldest != nil; oldest = g.orderList.Back() {
+		if oldest.Value.(ghostEntry[K]).added.After(expired) {
+			return
+		}
+		g.removeElement(oldest)
+	}
+}
+
+func (g *ghostList[K]) clear() {
+	clear(g.m)
+	g.orderList.Init()
+}
@@ -0,0 +1,22 @@
+package lrucache
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Key builds a composite string key from parts, each formatted with
+// fmt.Sprint and prefixed with its length, so that different splits of the
+// same characters such as ("a", "bc") and ("ab", "c") never produce the same
+// key. Parts that format alike, such as 1 and "1", do.
+func Key(parts ...any) string {
+	var b strings.Builder
+	for _, part := range parts {
+		s := fmt.Sprint(part)
+		b.WriteString(strconv.Itoa(len(s)))
+		b.WriteByte(':')
+		b.WriteString(s)
+	}
+	return b.String()
+}
@@ -0,0 +1,22 @@
+package lrucache
+
+import "testing"
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+	if Key("a", "bc") == Key("ab", "c") {
+		t.Errorf("expected different splits to produce different keys, but both are: %q", Key("a", "bc"))
+	}
+	if Key("a:", "b") == Key("a", ":b") {
+		t.Errorf("expected separators in parts not to collide, but both are: %q", Key("a:", "b"))
+	}
+	if Key("user", 42) != Key("user", 42) {
+		t.Error("expected the same parts to produce the same key")
+	}
+	if key := Key("user", 42); key != "4:user2:42" {
+		t.Errorf("expected key to be `4:user2:42`, but got: %q", key)
+	}
+	if key := Key(); key != "" {
+		t.Errorf("expected no parts to produce an empty key, but got: %q", key)
+	}
+}
@@ -0,0 +1,76 @@
+package lrucache
+
+// entryList is an intrusive doubly linked list of cache entries, ordered from
+// most to least recently used. Entries carry their own links, so unlike
+// container/list no separate element is allocated per entry and no type
+// assertion is needed to get from a node to its entry.
+type entryList[K comparable, V any] struct {
+	front, back *container[K, V]
+	len         int
+}
+
+// Init empties the list. Only the list's ends are reset, the removed entries
+// keep stale links and must not be reused.
+func (l *entryList[K, V]) Init() {
+	l.front, l.back, l.len = nil, nil, 0
+}
+
+func (l *entryList[K, V]) Len() int {
+	return l.len
+}
+
+// Front returns the most recently used entry, or nil if the list is empty.
+func (l *entryList[K, V]) Front() *container[K, V] {
+	return l.front
+}
+
+// Back returns the least recently used entry, or nil if the list is empty.
+func (l *entryList[K, V]) Back() *container[K, V] {
+	return l.back
+}
+
+func (l *entryList[K, V]) PushFront(e *container[K, V]) *container[K, V] {
+	e.prev, e.next = nil, l.front
+	if l.front != nil {
+		l.front.prev = e
+	} else {
+		l.back = e
+	}
+	l.front = e
+	l.len++
+	return e
+}
+
+// Remove unlinks e, which must be in the list.
+func (l *entryList[K, V]) Remove(e *container[K, V]) {
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		l.front = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	} else {
+		l.back = e.prev
+	}
+	e.prev, e.next = nil, nil
+	l.len--
+}
+
+func (l *entryList[K, V]) MoveToFront(e *container[K, V]) {
+	if l.front == e {
+		return
+	}
+	l.Remove(e)
+	l.PushFront(e)
+}
+
+// Next returns the next less recently used entry, or nil at the back.
+func (e *container[K, V]) Next() *container[K, V] {
+	return e.next
+}
+
+// Prev returns the next more recently used entry, or nil at the front.
+func (e *container[K, V]) Prev() *container[K, V] {
+	return e.prev
+}
@@ -0,0 +1,87 @@
+package lrucache
+
+import (
+	"container/list"
+	"slices"
+	"testing"
+)
+
+func listKeys(l *entryList[int, int]) []int {
+	var keys []int
+	for e := l.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+func TestEntryList(t *testing.T) {
+	t.Parallel()
+	l := &entryList[int, int]{}
+	if l.Front() != nil || l.Back() != nil || l.Len() != 0 {
+		t.Error("expected a new list to be empty")
+	}
+
+	entries := make([]*container[int, int], 4)
+	for i := range entries {
+		entries[i] = l.PushFront(&container[int, int]{key: i})
+	}
+	if keys := listKeys(l); !slices.Equal(keys, []int{3, 2, 1, 0}) {
+		t.Errorf("expected keys to be [3 2 1 0], but got: %v", keys)
+	}
+
+	l.MoveToFront(entries[0])
+	l.MoveToFront(entries[0])
+	l.Remove(entries[2])
+	if keys := listKeys(l); !slices.Equal(keys, []int{0, 3, 1}) {
+		t.Errorf("expected keys to be [0 3 1], but got: %v", keys)
+	}
+	if l.Len() != 3 || l.Front() != entries[0] || l.Back() != entries[1] {
+		t.Errorf("expected length 3 with front 0 and back 1, but got: %d, %d, %d", l.Len(), l.Front().key, l.Back().key)
+	}
+	var backwards []int
+	for e := l.Back(); e != nil; e = e.Prev() {
+		backwards = append(backwards, e.key)
+	}
+	if !slices.Equal(backwards, []int{1, 3, 0}) {
+		t.Errorf("expected keys from the back to be [1 3 0], but got: %v", backwards)
+	}
+
+	l.Remove(entries[0])
+	l.Remove(entries[1])
+	l.Remove(entries[3])
+	if l.Front() != nil || l.Back() != nil || l.Len() != 0 {
+		t.Error("expected the list to be empty after removing every entry")
+	}
+}
+
+// BenchmarkContainerList and BenchmarkEntryList compare the cost of inserting
+// an entry at the front and dropping the oldest one
+func BenchmarkContainerList(b *testing.B) {
+	l := list.New()
+	for i := range 1000 {
+		l.PushFront(&container[int, int]{key: i})
+	}
+
+	b.ReportAllocs()
+	i := 0
+	for b.Loop() {
+		l.Remove(l.Back())
+		l.PushFront(&container[int, int]{key: i})
+		i++
+	}
+}
+
+func BenchmarkEntryList(b *testing.B) {
+	l := &entryList[int, int]{}
+	for i := range 1000 {
+		l.PushFront(&container[int, int]{key: i})
+	}
+
+	b.ReportAllocs()
+	i := 0
+	for b.Loop() {
+		l.Remove(l.Back())
+		l.PushFront(&container[int, int]{key: i})
+		i++
+	}
+}
@@ -0,0 +1,383 @@
+package lrucache
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNoLoader is returned by operations that need a loader configured with
+// WithLoader when none is set.
+var ErrNoLoader = errors.New("no loader configured")
+
+// ErrComputeTimeout is returned by GetOrComputeWithTimeout when the compute
+// function does not finish in time.
+var ErrComputeTimeout = errors.New("compute timed out")
+
+var errComputePanicked = errors.New("compute function panicked")
+
+// call is an in-flight computation shared by all callers missing the same key
+type call[V any] struct {
+	wg    sync.WaitGroup
+	value V
+	err   error
+}
+
+// Source tells where a value returned by GetWithSource came from.
+type Source int
+
+const (
+	// SourceMiss means the key was not cached and no value was returned.
+	SourceMiss Source = iota
+	// SourceHit means the value was cached.
+	SourceHit
+	// SourceLoaded means the key was missing and the value was loaded with
+	// the WithLoader loader.
+	SourceLoaded
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceMiss:
+		return "miss"
+	case SourceHit:
+		return "hit"
+	case SourceLoaded:
+		return "loaded"
+	default:
+		return "unknown"
+	}
+}
+
+// GetRefresh reloads key with the WithLoader loader even when it is cached,
+// caching and returning the fresh value, for entries known to be stale. A
+// failed load leaves the cached value in place. It also retries keys whose
+// error is cached by WithErrorCaching. Concurrent refreshes and
+// loads of the same key share one call to the loader. It returns ErrNoLoader
+// when no loader is set.
+func (c *cache[K, V]) GetRefresh(key K) (V, error) {
+	if c.loader == nil {
+		var zero V
+		return zero, ErrNoLoader
+	}
+	value, _, err := c.runShared(key, func() (V, error) {
+		return c.loader(key)
+	}, true)
+	return value, err
+}
+
+// GetWithSource is Get that also reports where the value came from. With a
+// loader set by WithLoader, missing keys are loaded and cached like in
+// GetOrCompute, reporting SourceLoaded; a failed load reports SourceMiss.
+func (c *cache[K, V]) GetWithSource(key K) (V, Source, bool) {
+	if c.loader == nil {
+		value, ok := c.Get(key)
+		if !ok {
+			return value, SourceMiss, false
+		}
+		return value, SourceHit, true
+	}
+
+	if value, ok := c.lookup(key); ok {
+		return value, SourceHit, true
+	}
+	value, _, err := c.computeShared(key, func() (V, error) {
+		return c.loader(key)
+	})
+	if err != nil {
+		var zero V
+		return zero, SourceMiss, false
+	}
+	return value, SourceLoaded, true
+}
+
+// GetOrCompute returns the cached value for key, computing and caching it
+// with fn on a miss. Concurrent misses for the same key share a single call to
+// fn. Errors are returned to every waiting caller and are not cached.
+func (c *cache[K, V]) GetOrCompute(key K, fn func() (V, error)) (V, error) {
+	value, _, err := c.GetOrComputeShared(key, fn)
+	return value, err
+}
+
+// GetOrComputeShared is GetOrCompute that also reports whether the result was
+// shared, i.e. produced by another caller. Only the caller that actually ran
+// fn gets shared == false; callers that waited for it or found the value
+// already cached get true.
+func (c *cache[K, V]) GetOrComputeShared(key K, fn func() (V, error)) (value V, shared bool, err error) {
+	if value, ok := c.lookup(key); ok {
+		return value, true, nil
+	}
+	return c.computeShared(key, fn)
+}
+
+// lookup is Get for the GetOrCompute family. A miss is followed by taking the
+// write lock to store the computed value, so instead of taking it up front
+// like Get, lookup counts a key found missing under the read lock as a miss
+// right away; computeShared checks for the key again before running fn. Only
+// hits that need promoting take the write lock, looking the key up again.
+func (c *cache[K, V]) lookup(key K) (value V, ok bool) {
+	// ghost hits and near-eviction hits are recorded under the write lock
+	if c.ghosts != nil || c.onNearEviction != nil {
+		return c.Get(key)
+	}
+
+	c.auditGets(1)
+	value, ok, found := c.getFast(key, true)
+	if found && !ok {
+		c.lock.Lock()
+		value, ok, _ = c.getLocked(key)
+		c.unlock()
+	}
+	if c.onAccess != nil {
+		c.onAccess(key, ok)
+	}
+	return value, ok
+}
+
+// computeShared runs fn for a missing key unless a computation for it is
+// already in flight, in which case it waits for that one
+func (c *cache[K, V]) computeShared(key K, fn func() (V, error)) (value V, shared bool, err error) {
+	return c.runShared(key, fn, false)
+}
+
+// runShared is computeShared that with refresh set runs fn even when the key
+// has been cached meanwhile, replacing the cached value
+func (c *cache[K, V]) runShared(key K, fn func() (V, error), refresh bool) (value V, shared bool, err error) {
+	c.callsLock.Lock()
+	if cl, ok := c.calls[key]; ok {
+		c.callsLock.Unlock()
+		cl.wg.Wait()
+		return cl.value, true, cl.err
+	}
+	// a computation may have finished between the miss and taking callsLock
+	if value, ok := c.peek(key); ok && !refresh {
+		c.callsLock.Unlock()
+		return value, true, nil
+	}
+	// a refresh retries the loader even while its last error is cached
+	if err, ok := c.cachedError(key); ok && !refresh {
+		c.callsLock.Unlock()
+		var zero V
+		return zero, true, err
+	}
+	if c.calls == nil {
+		c.calls = make(map[K]*call[V])
+	}
+	cl := &call[V]{}
+	cl.wg.Add(1)
+	c.calls[key] = cl
+	c.callsLock.Unlock()
+
+	defer c.finishCall(key, cl)
+	// reported to waiting callers if fn panics
+	cl.err = errComputePanicked
+	cl.value, cl.err = c.load(fn)
+	if cl.err == nil {
+		c.Put(key, cl.value)
+	}
+	return cl.value, false, cl.err
+}
+
+// load runs fn, waiting for a slot first when WithMaxConcurrentLoads is set
+func (c *cache[K, V]) load(fn func() (V, error)) (V, error) {
+	if c.loadSlots != nil {
+		c.loadSlots <- struct{}{}
+		defer func() { <-c.loadSlots }()
+	}
+	c.stats.computations.Add(1)
+	value, err := fn()
+	if err != nil {
+		c.stats.loadFailures.Add(1)
+	} else {
+		c.stats.loadSuccesses.Add(1)
+	}
+	return value, err
+}
+
+func (c *cache[K, V]) finishCall(key K, cl *call[V]) {
+	c.callsLock.Lock()
+	delete(c.calls, key)
+	if c.failures != nil {
+		if cl.err != nil {
+			c.failures.set(key, cl.err, c.now())
+		} else {
+			c.failures.delete(key)
+		}
+	}
+	c.callsLock.Unlock()
+	cl.wg.Done()
+}
+
+// cachedError returns the error cached for key by WithErrorCaching, if it has
+// not expired yet
+func (c *cache[K, V]) cachedError(key K) (error, bool) {
+	if c.failures == nil {
+		return nil, false
+	}
+	return c.failures.get(key, c.now())
+}
+
+// Result is the outcome of an asynchronous computation.
+type Result[V any] struct {
+	Value V
+	Err   error
+}
+
+// GetOrComputeAsync is GetOrCompute that returns immediately. The returned
+// channel receives exactly one Result, right away on a hit or once fn
+// finishes on a miss, and is then closed. Concurrent requests for the same key
+// share one call to fn, and each channel gets its own delivery. A panic in fn
+// is recovered and delivered as an error.
+func (c *cache[K, V]) GetOrComputeAsync(key K, fn func() (V, error)) <-chan Result[V] {
+	results := make(chan Result[V], 1)
+	if value, ok := c.lookup(key); ok {
+		results <- Result[V]{Value: value}
+		close(results)
+		return results
+	}
+
+	go func() {
+		defer close(results)
+		defer func() {
+			if recover() != nil {
+				results <- Result[V]{Err: errComputePanicked}
+			}
+		}()
+		value, _, err := c.computeShared(key, fn)
+		results <- Result[V]{Value: value, Err: err}
+	}()
+	return results
+}
+
+// GetOrComputeWithTimeout is GetOrCompute that stops waiting after timeout
+// and returns ErrComputeTimeout. fn keeps running in the background, and its
+// value is cached for later callers if it eventually succeeds.
+func (c *cache[K, V]) GetOrComputeWithTimeout(key K, timeout time.Duration, fn func() (V, error)) (V, error) {
+	results := c.GetOrComputeAsync(key, fn)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case result := <-results:
+		return result.Value, result.Err
+	case <-timer.C:
+		var zero V
+		return zero, ErrComputeTimeout
+	}
+}
+
+// GetOrComputeOrDefault returns the cached value for key, computing it with fn
+// on a miss. If fn fails, def is returned instead of the error. With
+// WithCacheDefaults the default is also remembered for a while, during which
+// misses on key return it without running fn.
+func (c *cache[K, V]) GetOrComputeOrDefault(key K, fn func() (V, error), def V) V {
+	if value, ok := c.lookup(key); ok {
+		return value
+	}
+	if c.defaults != nil {
+		if value, ok := c.defaults.get(key, c.now()); ok {
+			return value
+		}
+	}
+
+	value, err := c.load(fn)
+	if err != nil {
+		if c.defaults != nil {
+			c.defaults.set(key, def, c.now())
+		}
+		return def
+	}
+
+	c.Put(key, value)
+	return value
+}
+
+// Warm loads every key not already cached using the loader configured with
+// WithLoader, running up to concurrency loads in parallel. Loads for keys that
+// are already being computed are shared. Failures do not stop the remaining
+// loads and are returned joined together.
+func (c *cache[K, V]) Warm(keys []K, concurrency int) error {
+	if c.loader == nil {
+		return ErrNoLoader
+	}
+	concurrency = max(concurrency, 1)
+
+	var (
+		wg      sync.WaitGroup
+		errLock sync.Mutex
+		errs    []error
+	)
+	work := make(chan K)
+	for range min(concurrency, len(keys)) {
+		wg.Go(func() {
+			for key := range work {
+				if _, ok := c.peek(key); ok {
+					continue
+				}
+				_, _, err := c.computeShared(key, func() (V, error) {
+					return c.loader(key)
+				})
+				if err != nil {
+					errLock.Lock()
+					errs = append(errs, fmt.Errorf("warm key %v: %w", key, err))
+					errLock.Unlock()
+				}
+			}
+		})
+	}
+	for _, key := range keys {
+		work <- key
+	}
+	close(work)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// GetMultiOrComputeParallel returns the values for keys, loading the missing
+// ones with fn using up to concurrency loads in parallel. Loaded values are
+// cached and loads for keys already being computed are shared. Keys that
+// fail to load are left out of the result, and their errors are returned
+// joined together.
+func (c *cache[K, V]) GetMultiOrComputeParallel(keys []K, fn func(K) (V, error), concurrency int) (map[K]V, error) {
+	found := make(map[K]V, len(keys))
+	var missing []K
+	for _, key := range keys {
+		if value, ok := c.lookup(key); ok {
+			found[key] = value
+		} else {
+			missing = append(missing, key)
+		}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+		errs []error
+	)
+	work := make(chan K)
+	for range min(max(concurrency, 1), len(missing)) {
+		wg.Go(func() {
+			for key := range work {
+				value, _, err := c.computeShared(key, func() (V, error) {
+					return fn(key)
+				})
+				lock.Lock()
+				if err != nil {
+					errs = append(errs, fmt.Errorf("load key %v: %w", key, err))
+				} else {
+					found[key] = value
+				}
+				lock.Unlock()
+			}
+		})
+	}
+	for _, key := range missing {
+		work <- key
+	}
+	close(work)
+	wg.Wait()
+
+	return found, errors.Join(errs...)
+}
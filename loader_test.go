@@ -0,0 +1,811 @@
+package lrucache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrComputeOrDefault(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, string](2)
+
+	val := cache.GetOrComputeOrDefault("key", func() (string, error) {
+		return "value", nil
+	}, "default")
+	if val != "value" {
+		t.Errorf("expected value to be `value`, but got: `%s`", val)
+	}
+	if cached, ok := cache.Get("key"); !ok || cached != "value" {
+		t.Errorf("expected computed value to be cached, but got: `%s`, %t", cached, ok)
+	}
+
+	val = cache.GetOrComputeOrDefault("failing", func() (string, error) {
+		return "", errors.New("loader failed")
+	}, "default")
+	if val != "default" {
+		t.Errorf("expected value to be `default`, but got: `%s`", val)
+	}
+	if _, ok := cache.Get("failing"); ok {
+		t.Error("default value should not be cached without WithCacheDefaults")
+	}
+}
+
+func TestGetOrComputeOrDefaultCachesDefault(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(2, WithCacheDefaults[string, string](time.Minute))
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	calls := 0
+	fn := func() (string, error) {
+		calls++
+		return "", errors.New("loader failed")
+	}
+
+	for range 2 {
+		val := cache.GetOrComputeOrDefault("key", fn, "default")
+		if val != "default" {
+			t.Errorf("expected value to be `default`, but got: `%s`", val)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected loader to be called once, but got: %d", calls)
+	}
+	if _, ok := cache.Get("key"); ok {
+		t.Error("expected the remembered default not to be an entry")
+	}
+
+	now = now.Add(time.Minute)
+	cache.GetOrComputeOrDefault("key", fn, "default")
+	if calls != 2 {
+		t.Errorf("expected loader to be retried after the ttl, but got calls: %d", calls)
+	}
+
+	cache.Clear()
+	cache.GetOrComputeOrDefault("key", fn, "default")
+	if calls != 3 {
+		t.Errorf("expected loader to be retried after Clear, but got calls: %d", calls)
+	}
+
+	val := cache.GetOrComputeOrDefault("key", func() (string, error) {
+		return "value", nil
+	}, "default")
+	if val != "default" {
+		t.Errorf("expected the remembered default, but got: `%s`", val)
+	}
+}
+
+func TestGetOrCompute(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](2)
+
+	calls := 0
+	fn := func() (int, error) {
+		calls++
+		return 42, nil
+	}
+	for range 3 {
+		val, err := cache.GetOrCompute("key", fn)
+		if err != nil {
+			t.Errorf("expected no error, but got: %v", err)
+		}
+		if val != 42 {
+			t.Errorf("expected value to be 42, but got: %d", val)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected fn to be called once, but got: %d", calls)
+	}
+
+	loadErr := errors.New("loader failed")
+	for range 2 {
+		_, err := cache.GetOrCompute("failing", func() (int, error) {
+			calls++
+			return 0, loadErr
+		})
+		if !errors.Is(err, loadErr) {
+			t.Errorf("expected error to be %v, but got: %v", loadErr, err)
+		}
+	}
+	// errors are not cached, so the second call computed again
+	if calls != 3 {
+		t.Errorf("expected fn to be called 3 times, but got: %d", calls)
+	}
+}
+
+func TestGetOrComputeShared(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](2)
+
+	var calls atomic.Int32
+	var leaders atomic.Int32
+	release := make(chan struct{})
+	fn := func() (int, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Go(func() {
+			val, shared, err := cache.GetOrComputeShared("key", fn)
+			if err != nil {
+				t.Errorf("expected no error, but got: %v", err)
+			}
+			if val != 42 {
+				t.Errorf("expected value to be 42, but got: %d", val)
+			}
+			if !shared {
+				leaders.Add(1)
+			}
+		})
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("expected fn to be called once, but got: %d", calls.Load())
+	}
+	if leaders.Load() != 1 {
+		t.Errorf("expected exactly one caller with shared=false, but got: %d", leaders.Load())
+	}
+}
+
+func TestGetOrComputePanic(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](2)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic from fn to propagate")
+			}
+		}()
+		cache.GetOrCompute("key", func() (int, error) {
+			panic("boom")
+		})
+	}()
+
+	// the in-flight call was cleaned up, so the key can be computed again
+	val, err := cache.GetOrCompute("key", func() (int, error) {
+		return 1, nil
+	})
+	if err != nil || val != 1 {
+		t.Errorf("expected value to be 1 without error, but got: %d, %v", val, err)
+	}
+}
+
+func TestGetOrComputeAsync(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](2)
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func() (int, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	var results []<-chan Result[int]
+	for range 10 {
+		results = append(results, cache.GetOrComputeAsync("key", fn))
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	for _, ch := range results {
+		result := <-ch
+		if result.Err != nil || result.Value != 42 {
+			t.Errorf("expected value to be 42 without error, but got: %d, %v", result.Value, result.Err)
+		}
+		if _, ok := <-ch; ok {
+			t.Error("expected channel to be closed after the result")
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected fn to be called once, but got: %d", calls.Load())
+	}
+
+	// a hit is delivered without computing
+	if result := <-cache.GetOrComputeAsync("key", fn); result.Value != 42 || calls.Load() != 1 {
+		t.Errorf("expected cached value 42 without another call, but got: %d after %d calls", result.Value, calls.Load())
+	}
+}
+
+func TestGetOrComputeAsyncPanic(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](2)
+
+	result := <-cache.GetOrComputeAsync("key", func() (int, error) {
+		panic("boom")
+	})
+	if !errors.Is(result.Err, errComputePanicked) {
+		t.Errorf("expected error to be %v, but got: %v", errComputePanicked, result.Err)
+	}
+}
+
+func TestComputeCount(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](2)
+	fn := func() (int, error) { return 1, nil }
+
+	// keys 0, 1 and 2 miss once each; 2 evicts 0, which then misses again
+	for _, key := range []int{0, 0, 1, 1, 2, 0, 0} {
+		if _, err := cache.GetOrCompute(key, fn); err != nil {
+			t.Errorf("expected no error, but got: %v", err)
+		}
+	}
+	if cache.ComputeCount() != 4 {
+		t.Errorf("expected 4 computations, but got: %d", cache.ComputeCount())
+	}
+
+	// followers of a shared computation are not counted
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			cache.GetOrCompute(10, func() (int, error) {
+				<-release
+				return 1, nil
+			})
+		})
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if cache.ComputeCount() != 5 {
+		t.Errorf("expected 5 computations, but got: %d", cache.ComputeCount())
+	}
+}
+
+func TestGetOrComputeWithTimeout(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](2)
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	_, err := cache.GetOrComputeWithTimeout("slow", 10*time.Millisecond, func() (int, error) {
+		defer close(done)
+		<-release
+		return 1, nil
+	})
+	if !errors.Is(err, ErrComputeTimeout) {
+		t.Errorf("expected error to be %v, but got: %v", ErrComputeTimeout, err)
+	}
+	if _, ok := cache.Peek("slow"); ok {
+		t.Error("expected nothing to be cached on timeout")
+	}
+
+	// the computation continues and caches its value once it succeeds
+	close(release)
+	<-done
+	time.Sleep(10 * time.Millisecond)
+	if value, ok := cache.Peek("slow"); !ok || value != 1 {
+		t.Errorf("expected late value 1 to be cached, but got: %d, %t", value, ok)
+	}
+
+	value, err := cache.GetOrComputeWithTimeout("fast", time.Second, func() (int, error) {
+		return 2, nil
+	})
+	if err != nil || value != 2 {
+		t.Errorf("expected value to be 2 without error, but got: %d, %v", value, err)
+	}
+}
+
+func TestMaxConcurrentLoads(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(100, WithMaxConcurrentLoads[int, int](3))
+
+	var inFlight, maxInFlight atomic.Int32
+	fn := func() (int, error) {
+		current := inFlight.Add(1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		inFlight.Add(-1)
+		return 1, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Go(func() {
+			if _, err := cache.GetOrCompute(i, fn); err != nil {
+				t.Errorf("expected no error, but got: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if maxInFlight.Load() > 3 {
+		t.Errorf("expected at most 3 loads in flight, but got: %d", maxInFlight.Load())
+	}
+	if cache.Len() != 50 {
+		t.Errorf("expected cache length to be 50, but got: %d", cache.Len())
+	}
+}
+
+func TestWarm(t *testing.T) {
+	t.Parallel()
+
+	var inFlight, maxInFlight, calls atomic.Int32
+	loadErr := errors.New("loader failed")
+	loader := func(key int) (int, error) {
+		calls.Add(1)
+		current := inFlight.Add(1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		inFlight.Add(-1)
+		if key == 13 {
+			return 0, loadErr
+		}
+		return key * 10, nil
+	}
+	cache, _ := New(100, WithLoader(loader))
+	cache.Put(0, -1)
+
+	keys := make([]int, 20)
+	for i := range keys {
+		keys[i] = i
+	}
+	err := cache.Warm(keys, 4)
+	if !errors.Is(err, loadErr) {
+		t.Errorf("expected error to wrap %v, but got: %v", loadErr, err)
+	}
+
+	if maxInFlight.Load() > 4 {
+		t.Errorf("expected at most 4 loads in flight, but got: %d", maxInFlight.Load())
+	}
+	// key 0 was already cached and must not be loaded again
+	if calls.Load() != 19 {
+		t.Errorf("expected loader to be called 19 times, but got: %d", calls.Load())
+	}
+	if val, _ := cache.Get(0); val != -1 {
+		t.Errorf("expected cached value of key 0 to be kept, but got: %d", val)
+	}
+	for _, key := range keys[1:] {
+		val, ok := cache.Get(key)
+		if key == 13 {
+			if ok {
+				t.Error("failed key: 13 should not be cached")
+			}
+			continue
+		}
+		if !ok || val != key*10 {
+			t.Errorf("expected key: %d to exists with value: %d, but got: %d, %t", key, key*10, val, ok)
+		}
+	}
+}
+
+func TestWarmWithoutLoader(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](10)
+
+	if err := cache.Warm([]int{1, 2}, 2); !errors.Is(err, ErrNoLoader) {
+		t.Errorf("expected error to be %v, but got: %v", ErrNoLoader, err)
+	}
+}
+
+func TestGetMultiOrComputeParallel(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](100)
+	cache.Put(0, -1)
+
+	loadErr := errors.New("loader failed")
+	var calls atomic.Int32
+	fn := func(key int) (int, error) {
+		calls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		if key == 7 {
+			return 0, loadErr
+		}
+		return key * 10, nil
+	}
+
+	keys := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	start := time.Now()
+	found, err := cache.GetMultiOrComputeParallel(keys, fn, 7)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, loadErr) {
+		t.Errorf("expected error to wrap %v, but got: %v", loadErr, err)
+	}
+	// 7 serial loads would take at least 140ms
+	if elapsed > 100*time.Millisecond {
+		t.Errorf("expected misses to load in parallel, but took: %s", elapsed)
+	}
+	if calls.Load() != 7 {
+		t.Errorf("expected fn to be called for the 7 misses, but got: %d", calls.Load())
+	}
+	expected := map[int]int{0: -1, 1: 10, 2: 20, 3: 30, 4: 40, 5: 50, 6: 60}
+	if len(found) != len(expected) {
+		t.Errorf("expected %d values, but got: %v", len(expected), found)
+	}
+	for key, value := range expected {
+		if found[key] != value {
+			t.Errorf("expected key: %d to have value: %d, but got: %d", key, value, found[key])
+		}
+	}
+	if value, ok := cache.Peek(3); !ok || value != 30 {
+		t.Errorf("expected loaded value to be cached, but got: %d, %t", value, ok)
+	}
+}
+
+func TestGetOrComputeConcurrentNoDuplicates(t *testing.T) {
+	t.Parallel()
+	const keys = 16
+	cache, _ := New[int, int](keys)
+
+	var calls [keys]atomic.Int32
+	var wg sync.WaitGroup
+	for g := range 32 {
+		wg.Go(func() {
+			for i := range 200 {
+				key := (g + i) % keys
+				val, err := cache.GetOrCompute(key, func() (int, error) {
+					calls[key].Add(1)
+					time.Sleep(time.Millisecond)
+					return key * 10, nil
+				})
+				if err != nil || val != key*10 {
+					t.Errorf("expected value to be %d, but got: %d, %v", key*10, val, err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	for key := range keys {
+		if n := calls[key].Load(); n != 1 {
+			t.Errorf("expected key %d to be computed once, but got: %d", key, n)
+		}
+	}
+	// each call is counted exactly once, whether it missed under the read lock
+	// or hit
+	hits, misses, _ := cache.Stats()
+	if hits+misses != 32*200 {
+		t.Errorf("expected %d lookups, but got: %d", 32*200, hits+misses)
+	}
+}
+
+func TestGetWithSource(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](2)
+	cache.Put("cached", 1)
+
+	if val, source, ok := cache.GetWithSource("cached"); !ok || source != SourceHit || val != 1 {
+		t.Errorf("expected a hit with value 1, but got: %d, %s, %t", val, source, ok)
+	}
+	if _, source, ok := cache.GetWithSource("absent"); ok || source != SourceMiss {
+		t.Errorf("expected a miss, but got: %s, %t", source, ok)
+	}
+
+	loadErr := errors.New("loader failed")
+	loading, _ := New(2, WithLoader(func(key string) (int, error) {
+		if key == "failing" {
+			return 0, loadErr
+		}
+		return len(key), nil
+	}))
+	if val, source, ok := loading.GetWithSource("absent"); !ok || source != SourceLoaded || val != 6 {
+		t.Errorf("expected the value 6 to be loaded, but got: %d, %s, %t", val, source, ok)
+	}
+	if val, source, ok := loading.GetWithSource("absent"); !ok || source != SourceHit || val != 6 {
+		t.Errorf("expected the loaded value to be cached, but got: %d, %s, %t", val, source, ok)
+	}
+	if _, source, ok := loading.GetWithSource("failing"); ok || source != SourceMiss {
+		t.Errorf("expected a failed load to be a miss, but got: %s, %t", source, ok)
+	}
+}
+
+func TestGetRefresh(t *testing.T) {
+	t.Parallel()
+	var calls atomic.Int32
+	release := make(chan struct{})
+	cache, _ := New(2, WithLoader(func(key string) (int, error) {
+		<-release
+		return int(calls.Add(1)) * 10, nil
+	}))
+	cache.Put("key", 1)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if val, err := cache.GetRefresh("key"); err != nil || val != 10 {
+				t.Errorf("expected the refreshed value to be 10, but got: %d, %v", val, err)
+			}
+		})
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("expected concurrent refreshes to share one load, but got: %d", calls.Load())
+	}
+	if val, ok := cache.Peek("key"); !ok || val != 10 {
+		t.Errorf("expected the cached value to be refreshed to 10, but got: %d, %t", val, ok)
+	}
+	// a cached value does not stop the next refresh from loading
+	if val, err := cache.GetRefresh("key"); err != nil || val != 20 {
+		t.Errorf("expected the refreshed value to be 20, but got: %d, %v", val, err)
+	}
+
+	plain, _ := New[string, int](2)
+	if _, err := plain.GetRefresh("key"); !errors.Is(err, ErrNoLoader) {
+		t.Errorf("expected ErrNoLoader, but got: %v", err)
+	}
+}
+
+func TestGetOrComputeConcurrentClear(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](10)
+
+	// run with -race: computations are counted without the cache lock
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Go(func() {
+		defer close(done)
+		for key := range 1000 {
+			cache.GetOrCompute(key, func() (int, error) { return key, nil })
+		}
+	})
+	wg.Go(func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				cache.Clear()
+			}
+		}
+	})
+	wg.Wait()
+
+	cache.Clear()
+	if count := cache.ComputeCount(); count != 0 {
+		t.Errorf("expected Clear to reset the compute count, but got: %d", count)
+	}
+}
+
+// lockCheckSink reports misses counted while the cache lock is not held
+type lockCheckSink struct {
+	cache    *cache[int, int]
+	unlocked atomic.Int32
+}
+
+func (s *lockCheckSink) IncHits()      {}
+func (s *lockCheckSink) IncEvictions() {}
+func (s *lockCheckSink) IncMisses() {
+	if s.cache.lock.TryLock() {
+		s.cache.lock.Unlock()
+		s.unlocked.Add(1)
+	}
+}
+
+func TestGetOrComputeMissConcurrentClear(t *testing.T) {
+	t.Parallel()
+	sink := &lockCheckSink{}
+	cache, _ := New(10, WithHitRateWindow[int, int](100), WithStatsSink[int, int](sink))
+	sink.cache = cache
+
+	// run with -race: misses are counted while Clear resets the stats and
+	// the hit rate window under the write lock
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Go(func() {
+		defer close(done)
+		for key := range 1000 {
+			cache.GetOrComputeOrDefault(key, func() (int, error) { return key, nil }, -1)
+		}
+	})
+	wg.Go(func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				cache.Clear()
+			}
+		}
+	})
+	wg.Wait()
+
+	if n := sink.unlocked.Load(); n != 0 {
+		t.Errorf("expected misses to be counted under the cache lock, but %d were not", n)
+	}
+}
+
+func TestLoadCountersConcurrentClear(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](10)
+	loadErr := errors.New("loader failed")
+
+	// run with -race: load successes and failures are counted without the
+	// cache lock
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Go(func() {
+		defer close(done)
+		for key := range 1000 {
+			cache.GetOrCompute(key, func() (int, error) {
+				if key%2 == 1 {
+					return 0, loadErr
+				}
+				return key, nil
+			})
+		}
+	})
+	wg.Go(func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				cache.Clear()
+			}
+		}
+	})
+	wg.Wait()
+
+	cache.Clear()
+	if stats := cache.StatsStruct(); stats.LoadSuccesses != 0 || stats.LoadFailures != 0 {
+		t.Errorf("expected Clear to reset the load counters, but got: %d, %d", stats.LoadSuccesses, stats.LoadFailures)
+	}
+}
+
+func TestLoadSuccessesAndFailures(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](10)
+	loadErr := errors.New("loader failed")
+	fn := func(key int) func() (int, error) {
+		return func() (int, error) {
+			if key%2 == 1 {
+				return 0, loadErr
+			}
+			return key, nil
+		}
+	}
+	for key := range 5 {
+		cache.GetOrCompute(key, fn(key))
+	}
+	// hits do not load, failed keys load again
+	cache.GetOrCompute(0, fn(0))
+	cache.GetOrCompute(1, fn(1))
+	cache.GetOrComputeOrDefault(7, fn(7), -1)
+
+	stats := cache.StatsStruct()
+	if stats.LoadSuccesses != 3 {
+		t.Errorf("expected 3 load successes, but got: %d", stats.LoadSuccesses)
+	}
+	if stats.LoadFailures != 4 {
+		t.Errorf("expected 4 load failures, but got: %d", stats.LoadFailures)
+	}
+
+	cache.Clear()
+	if stats := cache.StatsStruct(); stats.LoadSuccesses != 0 || stats.LoadFailures != 0 {
+		t.Errorf("expected Clear to reset the load counters, but got: %d, %d", stats.LoadSuccesses, stats.LoadFailures)
+	}
+}
+
+func TestErrorCaching(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(2, WithErrorCaching[string, int](time.Second))
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+
+	calls := 0
+	loadErr := errors.New("backend unavailable")
+	fn := func() (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, loadErr
+		}
+		return 42, nil
+	}
+
+	for range 3 {
+		if _, err := cache.GetOrCompute("key", fn); !errors.Is(err, loadErr) {
+			t.Errorf("expected the cached error, but got: %v", err)
+		}
+		now = now.Add(300 * time.Millisecond)
+	}
+	if calls != 1 {
+		t.Errorf("expected fn not to be retried within the ttl, but got %d calls", calls)
+	}
+	// other keys are not affected
+	if val, err := cache.GetOrCompute("other", fn); err != nil || val != 42 {
+		t.Errorf("expected value to be 42, but got: %d, %v", val, err)
+	}
+
+	now = now.Add(100 * time.Millisecond)
+	if val, err := cache.GetOrCompute("key", fn); err != nil || val != 42 {
+		t.Errorf("expected the call after the ttl to retry, but got: %d, %v", val, err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, but got: %d", calls)
+	}
+	if len(cache.failures.m) != 0 {
+		t.Errorf("expected the expired error to be dropped, but got: %v", cache.failures.m)
+	}
+}
+
+func TestErrorCachingPruneAndClear(t *testing.T) {
+	t.Parallel()
+	cache, _ := New(2, WithErrorCaching[int, int](time.Second))
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+
+	loadErr := errors.New("backend unavailable")
+	fail := func() (int, error) { return 0, loadErr }
+	for key := range 100 {
+		cache.GetOrCompute(key, fail)
+	}
+	if len(cache.failures.m) != 100 {
+		t.Errorf("expected 100 cached errors, but got: %d", len(cache.failures.m))
+	}
+
+	// errors of other keys expire without a new error being cached
+	now = now.Add(time.Second)
+	if val, err := cache.GetOrCompute(-1, func() (int, error) { return 1, nil }); err != nil || val != 1 {
+		t.Errorf("expected value to be 1, but got: %d, %v", val, err)
+	}
+	if len(cache.failures.m) != 0 || cache.failures.orderList.Len() != 0 {
+		t.Errorf("expected the expired errors to be pruned, but got: %d", len(cache.failures.m))
+	}
+
+	cache.GetOrCompute(0, fail)
+	cache.Clear()
+	calls := 0
+	if val, err := cache.GetOrCompute(0, func() (int, error) {
+		calls++
+		return 2, nil
+	}); err != nil || val != 2 || calls != 1 {
+		t.Errorf("expected Clear to drop the cached error, but got: %d, %v", val, err)
+	}
+}
+
+func TestGetRefreshSkipsCachedError(t *testing.T) {
+	t.Parallel()
+	calls := 0
+	loadErr := errors.New("backend unavailable")
+	load := func() (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, loadErr
+		}
+		return 42, nil
+	}
+	cache, _ := New(2, WithErrorCaching[string, int](time.Minute), WithLoader(func(string) (int, error) {
+		return load()
+	}))
+
+	if _, err := cache.GetOrCompute("key", load); !errors.Is(err, loadErr) {
+		t.Fatalf("expected the loader error, but got: %v", err)
+	}
+	if val, err := cache.GetRefresh("key"); err != nil || val != 42 {
+		t.Errorf("expected GetRefresh to retry the loader, but got: %d, %v", val, err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 loader calls, but got: %d", calls)
+	}
+	// the successful refresh forgets the cached error
+	cache.Delete("key")
+	if val, err := cache.GetOrCompute("key", load); err != nil || val != 42 {
+		t.Errorf("expected the cached error to be dropped, but got: %d, %v", val, err)
+	}
+}
@@ -0,0 +1,30 @@
+// Package lruexpvar publishes lrucache statistics through the standard
+// library's expvar package.
+package lruexpvar
+
+import (
+	"expvar"
+
+	lrucache "github.com/aditya1944/lru-cache"
+)
+
+// Cache is the subset of the lrucache cache methods used to read statistics.
+type Cache interface {
+	StatsStruct() lrucache.StatsSnapshot
+}
+
+// PublishExpvar registers an expvar.Func under name that reports the hits,
+// misses, evictions, length and capacity of cache as a JSON object. Like
+// expvar.Publish it panics if name is already registered.
+func PublishExpvar(name string, cache Cache) {
+	expvar.Publish(name, expvar.Func(func() any {
+		stats := cache.StatsStruct()
+		return map[string]uint64{
+			"hits":      stats.Hits,
+			"misses":    stats.Misses,
+			"evictions": stats.Evictions,
+			"len":       uint64(stats.Length),
+			"capacity":  uint64(stats.Capacity),
+		}
+	}))
+}
@@ -0,0 +1,40 @@
+package lruexpvar
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	lrucache "github.com/aditya1944/lru-cache"
+)
+
+// published counts the names published by the tests. expvar names are global
+// to the process and can only be published once, so each run takes a new one
+// for -count above 1 to work.
+var published atomic.Uint64
+
+func TestPublishExpvar(t *testing.T) {
+	t.Parallel()
+	cache, _ := lrucache.New[string, int](2)
+	name := fmt.Sprintf("%s_%d", t.Name(), published.Add(1))
+	PublishExpvar(name, cache)
+
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	cache.Put("c", 3)
+	cache.Get("c")
+	cache.Get("a")
+
+	var stats map[string]uint64
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &stats); err != nil {
+		t.Fatalf("expected published stats to be a JSON object, but got: %v", err)
+	}
+	expected := map[string]uint64{"hits": 1, "misses": 1, "evictions": 1, "len": 2, "capacity": 2}
+	for field, value := range expected {
+		if stats[field] != value {
+			t.Errorf("expected %s to be %d, but got: %d", field, value, stats[field])
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// Package lruotel adds OpenTelemetry tracing to lrucache caches. It lives in
+// its own module so that the core cache stays free of dependencies.
+package lruotel
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/aditya1944/lru-cache/lruotel"
+
+// Cache is the subset of the lrucache cache methods that are traced.
+type Cache[K comparable, V any] interface {
+	GetCtx(ctx context.Context, key K) (V, bool, error)
+	Put(key K, value V)
+	Len() int
+}
+
+// Option configures a traced cache.
+type Option func(*config)
+
+type config struct {
+	tracer trace.Tracer
+}
+
+// WithTracer sets the tracer used to start spans. By default the tracer of
+// the global tracer provider is used.
+func WithTracer(tracer trace.Tracer) Option {
+	return func(c *config) {
+		c.tracer = tracer
+	}
+}
+
+type tracedCache[K comparable, V any] struct {
+	cache  Cache[K, V]
+	tracer trace.Tracer
+}
+
+// Wrap returns a cache whose context-aware methods record a child span of
+// the span carried by the context.
+func Wrap[K comparable, V any](cache Cache[K, V], opts ...Option) *tracedCache[K, V] {
+	cfg := config{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.tracer == nil {
+		cfg.tracer = otel.Tracer(instrumentationName)
+	}
+	return &tracedCache[K, V]{cache: cache, tracer: cfg.tracer}
+}
+
+// GetCtx looks up key in a span named "lrucache.Get", recording whether it
+// was a hit and the number of cached entries.
+func (t *tracedCache[K, V]) GetCtx(ctx context.Context, key K) (V, bool, error) {
+	ctx, span := t.tracer.Start(ctx, "lrucache.Get")
+	defer span.End()
+
+	value, ok, err := t.cache.GetCtx(ctx, key)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return value, ok, err
+	}
+	span.SetAttributes(
+		attribute.Bool("lrucache.hit", ok),
+		attribute.Int("lrucache.len", t.cache.Len()),
+	)
+	return value, ok, nil
+}
+
+// PutCtx stores the value in a span named "lrucache.Put", recording the
+// number of cached entries afterwards.
+func (t *tracedCache[K, V]) PutCtx(ctx context.Context, key K, value V) {
+	_, span := t.tracer.Start(ctx, "lrucache.Put")
+	defer span.End()
+
+	t.cache.Put(key, value)
+	span.SetAttributes(attribute.Int("lrucache.len", t.cache.Len()))
+}
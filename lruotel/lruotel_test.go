@@ -0,0 +1,72 @@
+package lruotel
+
+import (
+	"context"
+	"testing"
+
+	lrucache "github.com/aditya1944/lru-cache"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, attr := range span.Attributes() {
+		attrs[attr.Key] = attr.Value
+	}
+	return attrs
+}
+
+func TestTracedCache(t *testing.T) {
+	t.Parallel()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	tracer := provider.Tracer("test")
+
+	cache, _ := lrucache.New[string, string](10)
+	traced := Wrap[string, string](cache, WithTracer(tracer))
+
+	ctx, parent := tracer.Start(context.Background(), "request")
+	traced.PutCtx(ctx, "key", "value")
+	val, ok, err := traced.GetCtx(ctx, "key")
+	if err != nil || !ok || val != "value" {
+		t.Errorf("expected value to be `value`, but got: `%s`, %t, %v", val, ok, err)
+	}
+	traced.GetCtx(ctx, "missing")
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("expected 4 spans, but got: %d", len(spans))
+	}
+
+	expected := []struct {
+		name     string
+		checkHit bool
+		hit      bool
+	}{
+		{name: "lrucache.Put"},
+		{name: "lrucache.Get", checkHit: true, hit: true},
+		{name: "lrucache.Get", checkHit: true, hit: false},
+	}
+	for i, want := range expected {
+		span := spans[i]
+		if span.Name() != want.name {
+			t.Errorf("expected span %d to be named %s, but got: %s", i, want.name, span.Name())
+		}
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("expected span %d to be a child of the request span", i)
+		}
+		attrs := spanAttributes(span)
+		if attrs["lrucache.len"].AsInt64() != 1 {
+			t.Errorf("expected span %d len attribute to be 1, but got: %v", i, attrs["lrucache.len"])
+		}
+		if !want.checkHit {
+			continue
+		}
+		if hit, ok := attrs["lrucache.hit"]; !ok || hit.AsBool() != want.hit {
+			t.Errorf("expected span %d hit attribute to be %t, but got: %v", i, want.hit, hit)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+package lrucache
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Option configures optional cache behaviour at construction time.
+type Option[K comparable, V any] func(*cache[K, V])
+
+// WithEqualFunc sets the function used to compare values, e.g. in
+// CompareAndDelete and Diff. Without it values are compared with ==, which panics
+// when V holds an uncomparable type such as a slice or map.
+func WithEqualFunc[K comparable, V any](equal func(a, b V) bool) Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.equal = equal
+	}
+}
+
+// WithInitialMapSize sets the number of entries the backing map is
+// pre-allocated for. By default it is sized to capacity; a smaller hint saves
+// memory for large caches that usually stay sparse, at the cost of the map
+// growing (and rehashing) as entries are added. Hints above capacity are
+// clamped to capacity.
+func WithInitialMapSize[K comparable, V any](size uint) Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.initialMapSize = size
+	}
+}
+
+// WithEvictionComparator replaces recency based victim selection: when the
+// cache is full, the entry that is least according to less is evicted, with
+// ties going to the least recently used entry. Besides the key, value and
+// priority, EntryInfo carries the insertion order and hit count of the entry,
+// for comparators such as oldest first or least accessed. Finding the victim
+// scans every entry, so each eviction costs O(n) instead of O(1).
+func WithEvictionComparator[K comparable, V any](less func(a, b EntryInfo[K, V]) bool) Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.evictionLess = less
+	}
+}
+
+// WithEvictionVeto calls veto with the entry chosen for eviction; returning
+// true protects it, and the next more recently used entry is tried instead.
+// After 8 vetoes in a row the chosen entry is evicted anyway, so that
+// inserts cannot stall. veto runs under the write lock and must not call
+// other cache methods.
+func WithEvictionVeto[K comparable, V any](veto func(K, V) bool) Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.evictionVeto = veto
+	}
+}
+
+// WithCacheDefaults makes GetOrComputeOrDefault remember the default value
+// for ttl when the loader fails, so that calls for the same key return it
+// without retrying the loader until then. The default is not stored as an
+// entry, so Get and the other lookups keep missing, and the first call after
+// ttl retries the loader. Clear forgets the remembered defaults.
+func WithCacheDefaults[K comparable, V any](ttl time.Duration) Option[K, V] {
+	return func(c *cache[K, V]) {
+		if ttl > 0 {
+			c.defaults = newExpiring[K, V](ttl)
+		}
+	}
+}
+
+// WithEvictionLog keeps the last size evictions in a ring buffer, readable
+// through RecentEvictions. Older events are overwritten once the buffer is full.
+func WithEvictionLog[K comparable, V any](size int) Option[K, V] {
+	return func(c *cache[K, V]) {
+		if size > 0 {
+			c.evictionLog = newEvictionLog[K](size)
+		}
+	}
+}
+
+// WithGhostList remembers the keys of the last size evicted entries. A miss on
+// a remembered key counts as a ghost hit, reported by GhostHits: it would have
+// been a hit if the cache were up to size entries larger.
+func WithGhostList[K comparable, V any](size int) Option[K, V] {
+	return func(c *cache[K, V]) {
+		if size > 0 {
+			c.ghosts = newGhostList[K](size)
+		}
+	}
+}
+
+// WithGhostTTL makes the WithGhostList list forget keys d after their
+// eviction, so that ghost hits reflect recent behaviour in long running
+// processes rather than evictions from long ago.
+func WithGhostTTL[K comparable, V any](d time.Duration) Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.ghostTTL = d
+	}
+}
+
+// WithMaxConcurrentLoads bounds how many loader functions passed to the
+// GetOrCompute family run at the same time across all keys. Callers beyond
+// the limit block until a running load finishes.
+func WithMaxConcurrentLoads[K comparable, V any](n int) Option[K, V] {
+	return func(c *cache[K, V]) {
+		if n > 0 {
+			c.loadSlots = make(chan struct{}, n)
+		}
+	}
+}
+
+// WithLoader sets the function used to load missing keys by operations that
+// do not take one per call, such as Warm.
+func WithLoader[K comparable, V any](loader func(K) (V, error)) Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.loader = loader
+	}
+}
+
+// WithStableOrder stops value updates of existing keys, through Put and the
+// other write methods, from promoting the entry. Only reads change recency, so
+// entries keep their insertion order until they are accessed.
+func WithStableOrder[K comparable, V any]() Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.stableOrder = true
+	}
+}
+
+// WithEvictionCallbackEvery calls fn with a stats snapshot every time the
+// evictions counter reaches a multiple of n. Each multiple fires exactly once,
+// after the lock of the evicting operation is released.
+func WithEvictionCallbackEvery[K comparable, V any](n uint64, fn func(StatsSnapshot)) Option[K, V] {
+	return func(c *cache[K, V]) {
+		if n > 0 && fn != nil {
+			c.evictionEvery = n
+			c.onEvictionEvery = fn
+		}
+	}
+}
+
+// WithOnAccess calls fn after every Get with the key and whether it was a hit.
+// fn runs after the lock is released, on the calling goroutine.
+func WithOnAccess[K comparable, V any](fn func(key K, hit bool)) Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.onAccess = fn
+	}
+}
+
+// WithPriorityWindow sets how many of the least recently used entries are
+// compared when entries have priorities set with PutWithPriority (default 8).
+// A wider window protects high priority entries better but makes each
+// eviction scan more entries.
+func WithPriorityWindow[K comparable, V any](n int) Option[K, V] {
+	return func(c *cache[K, V]) {
+		if n > 0 {
+			c.priorityWindow = n
+		}
+	}
+}
+
+// WithSkipEqualPuts makes Put and the other write methods ignore updates of an
+// existing key whose new value is equal to the cached one according to eq:
+// the entry keeps its value, version and position in the recency order.
+func WithSkipEqualPuts[K comparable, V any](eq func(a, b V) bool) Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.skipEqual = eq
+	}
+}
+
+// WithEvictBatch makes an insert into a full cache evict n entries at once
+// instead of one, so that the following n-1 inserts need no eviction. This
+// amortizes eviction work over several Puts at the cost of running up to n-1
+// entries below capacity.
+func WithEvictBatch[K comparable, V any](n uint) Option[K, V] {
+	return func(c *cache[K, V]) {
+		if n > 0 {
+			c.evictBatch = n
+		}
+	}
+}
+
+// WithReferenceBit replaces exact LRU ordering with the CLOCK approximation:
+// Get marks the entry as referenced under the read lock instead of promoting
+// it, and eviction gives referenced entries a second chance by clearing their
+// bit and moving them to the front. Hits no longer take the write lock, at
+// the cost of less precise recency.
+func WithReferenceBit[K comparable, V any]() Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.referenceBit = true
+	}
+}
+
+// WithMaxKeySize rejects new keys whose size is above n: Put and the other
+// write methods ignore them and PutChecked returns ErrKeyTooLarge. The size of
+// a key is measured with size, or with len for string keys when size is nil.
+// Keys of other types are not limited without a size function.
+func WithMaxKeySize[K comparable, V any](n int, size func(K) int) Option[K, V] {
+	return func(c *cache[K, V]) {
+		if size == nil {
+			size = func(key K) int {
+				if s, ok := any(key).(string); ok {
+					return len(s)
+				}
+				return 0
+			}
+		}
+		c.maxKeySize = n
+		c.keySize = size
+	}
+}
+
+// WithEvictionSampleRate calls fn with every nth eviction, giving a
+// representative sample of evictions without the volume of logging each one.
+// fn runs after the lock of the evicting operation is released.
+func WithEvictionSampleRate[K comparable, V any](every int, fn func(EvictionEvent[K])) Option[K, V] {
+	return func(c *cache[K, V]) {
+		if every > 0 && fn != nil {
+			c.sampleEvery = every
+			c.onSample = fn
+		}
+	}
+}
+
+// WithOnEvict calls fn with the key, value and reason of every entry evicted
+// from the cache, including the entries removed by Purge. fn runs after the
+// lock of the evicting operation is released, on the calling goroutine.
+func WithOnEvict[K comparable, V any](fn func(key K, value V, reason EvictionReason)) Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.onEvict = fn
+	}
+}
+
+// WithHitRateWindow tracks whether each of the last size lookups hit, for
+// WindowedHitRatio.
+func WithHitRateWindow[K comparable, V any](size int) Option[K, V] {
+	return func(c *cache[K, V]) {
+		if size > 0 {
+			c.hitWindow = newHitWindow(size)
+		}
+	}
+}
+
+// WithNearEvictionHook calls fn with the key of every Get hit on an entry that
+// was among the window least recently used entries, i.e. about to be evicted,
+// before the hit promoted it. Keys that keep being rescued at the last moment
+// hint that the cache is too small. fn runs after the lock is released. While
+// the hook is set every Get takes the write lock.
+func WithNearEvictionHook[K comparable, V any](window int, fn func(K)) Option[K, V] {
+	return func(c *cache[K, V]) {
+		if window > 0 && fn != nil {
+			c.nearEvictionWindow = window
+			c.onNearEviction = fn
+		}
+	}
+}
+
+// WithStatsSink reports every hit, miss and eviction to sink in addition to
+// the internal counters read by Stats.
+func WithStatsSink[K comparable, V any](sink StatsSink) Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.sink = sink
+	}
+}
+
+// WithElementPool recycles the entries of evicted keys for new keys through a
+// sync.Pool, saving an allocation per insert into a full cache. It helps
+// caches with high churn; recycled entries are cleared so that they do not
+// keep evicted keys or values alive.
+func WithElementPool[K comparable, V any]() Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.pool = &sync.Pool{}
+	}
+}
+
+// WithStatsLogger writes a line with the current statistics to w every
+// interval, from a background goroutine that runs until Close is called. It is
+// meant for local debugging; w must be safe for use from that goroutine.
+func WithStatsLogger[K comparable, V any](w io.Writer, interval time.Duration) Option[K, V] {
+	return func(c *cache[K, V]) {
+		if w != nil && interval > 0 {
+			c.statsLog = &statsLogger{w: w, interval: interval}
+		}
+	}
+}
+
+// WithStatsAudit tallies lookups and puts independently of the statistics, so
+// that AuditStats can cross-check the two. It is a debugging aid for catching
+// miscounted statistics.
+func WithStatsAudit[K comparable, V any]() Option[K, V] {
+	return func(c *cache[K, V]) {
+		c.audit = &statsAudit{}
+	}
+}
+
+// WithErrorCaching caches the error of a failed loader or compute function of
+// the GetOrCompute family for ttl, during which calls for the same key return
+// it without running the function again, so that a failing backend is not hit
+// by a storm of retries. The first call after ttl retries. Only errors are
+// cached this way; values, including ones meaning "not found", are cached as
+// usual. GetOrComputeOrDefault, which falls back to its default instead of
+// returning errors, does not cache them. Clear forgets the cached errors.
+func WithErrorCaching[K comparable, V any](ttl time.Duration) Option[K, V] {
+	return func(c *cache[K, V]) {
+		if ttl > 0 {
+			c.failures = newExpiring[K, error](ttl)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package lrucache
+
+import "cmp"
+
+// orderedCache is a cache with ordered keys, which adds range queries over
+// the key order
+type orderedCache[K cmp.Ordered, V any] struct {
+	*cache[K, V]
+}
+
+// NewOrdered is New for ordered key types, returning a cache that also
+// supports RangeKeys.
+func NewOrdered[K cmp.Ordered, V any](capacity uint, opts ...Option[K, V]) (*orderedCache[K, V], error) {
+	c, err := New(capacity, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &orderedCache[K, V]{cache: c}, nil
+}
+
+// RangeKeys calls fn for every entry whose key is within [lo, hi], from most
+// to least recently used, until fn returns false. The recency list is not
+// sorted by key, so every entry is scanned: it is O(n) under the read lock
+// whatever the size of the range. fn must not call methods that modify the
+// cache.
+func (c *orderedCache[K, V]) RangeKeys(lo, hi K, fn func(K, V) bool) {
+	c.RangeMatch(func(key K) bool {
+		return key >= lo && key <= hi
+	}, fn)
+}
@@ -0,0 +1,46 @@
+package lrucache
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRangeKeys(t *testing.T) {
+	t.Parallel()
+	if _, err := NewOrdered[int, int](0); err == nil {
+		t.Error("expected an error for a capacity of 0")
+	}
+
+	cache, _ := NewOrdered[int, string](10)
+	for _, key := range []int{5, 1, 9, 3, 7} {
+		cache.Put(key, "value")
+	}
+
+	var keys []int
+	cache.RangeKeys(3, 7, func(key int, value string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	// in recency order, bounds included
+	if !slices.Equal(keys, []int{7, 3, 5}) {
+		t.Errorf("expected keys [7 3 5], but got: %v", keys)
+	}
+
+	keys = keys[:0]
+	cache.RangeKeys(10, 20, func(key int, value string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if len(keys) != 0 {
+		t.Errorf("expected no keys in range, but got: %v", keys)
+	}
+
+	visited := 0
+	cache.RangeKeys(0, 10, func(int, string) bool {
+		visited++
+		return false
+	})
+	if visited != 1 {
+		t.Errorf("expected the scan to stop when fn returns false, but visited: %d", visited)
+	}
+}
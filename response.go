@@ -0,0 +1,62 @@
+package lrucache
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// NewBytesCache creates a cache of raw byte payloads keyed by string, the
+// common shape for HTTP and blob caches. Values are compared with bytes.Equal,
+// as slices cannot be compared with ==, unless opts set another WithEqualFunc.
+func NewBytesCache(capacity uint, opts ...Option[string, []byte]) (*cache[string, []byte], error) {
+	return New(capacity, append([]Option[string, []byte]{WithEqualFunc[string](bytes.Equal)}, opts...)...)
+}
+
+// Response is a cached HTTP response.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+type responseCache struct {
+	cache *cache[string, Response]
+}
+
+// PutResponse stores a copy of resp, so the caller may keep modifying its
+// header and body afterwards.
+func (r *responseCache) PutResponse(key string, resp Response) {
+	r.cache.Put(key, Response{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       bytes.Clone(resp.Body),
+	})
+}
+
+// GetResponse returns the cached response for key. The returned header and
+// body are shared with the cache and must not be modified.
+func (r *responseCache) GetResponse(key string) (Response, bool) {
+	return r.cache.Get(key)
+}
+
+func (r *responseCache) Delete(key string) {
+	r.cache.Delete(key)
+}
+
+func (r *responseCache) Len() int {
+	return r.cache.Len()
+}
+
+func (r *responseCache) Stats() (hits uint64, misses uint64, evictions uint64) {
+	return r.cache.Stats()
+}
+
+// NewResponseCache creates a cache of HTTP responses keyed by string, e.g. by
+// request method and URL.
+func NewResponseCache(capacity uint, opts ...Option[string, Response]) (*responseCache, error) {
+	c, err := New(capacity, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &responseCache{cache: c}, nil
+}
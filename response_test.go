@@ -0,0 +1,72 @@
+package lrucache
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestBytesCache(t *testing.T) {
+	t.Parallel()
+	cache, err := NewBytesCache(1)
+	if err != nil {
+		t.Fatalf("expected no error, but got: %v", err)
+	}
+
+	cache.Put("a", []byte("body-a"))
+	cache.Put("b", []byte("body-b"))
+
+	if _, ok := cache.Get("a"); ok {
+		t.Error("key: `a` should have been evicted, but still exists")
+	}
+	if val, ok := cache.Get("b"); !ok || string(val) != "body-b" {
+		t.Errorf("expected value to be `body-b`, but got: `%s`", val)
+	}
+
+	// byte slices are compared by content rather than with ==
+	if cache.CompareAndDelete("b", []byte("other")) {
+		t.Error("expected CompareAndDelete not to delete a different body")
+	}
+	if !cache.CompareAndDelete("b", []byte("body-b")) {
+		t.Error("expected CompareAndDelete to delete an equal body")
+	}
+}
+
+func TestResponseCache(t *testing.T) {
+	t.Parallel()
+	_, err := NewResponseCache(0)
+	if err == nil {
+		t.Error("NewResponseCache should return error when capacity is 0")
+	}
+
+	cache, _ := NewResponseCache(2)
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	body := []byte(`{"id":1}`)
+	cache.PutResponse("GET /users/1", Response{StatusCode: http.StatusOK, Header: header, Body: body})
+
+	// the cache keeps its own copy of the response
+	header.Set("Content-Type", "text/plain")
+	body[0] = 'x'
+
+	resp, ok := cache.GetResponse("GET /users/1")
+	if !ok {
+		t.Fatal("expected response to exists, but do not exists")
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status code to be %d, but got: %d", http.StatusOK, resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("expected content type to be `application/json`, but got: `%s`", contentType)
+	}
+	if string(resp.Body) != `{"id":1}` {
+		t.Errorf("expected body to be `{\"id\":1}`, but got: `%s`", resp.Body)
+	}
+
+	cache.PutResponse("GET /users/2", Response{StatusCode: http.StatusNotFound})
+	cache.PutResponse("GET /users/3", Response{StatusCode: http.StatusOK})
+	if _, ok := cache.GetResponse("GET /users/1"); ok {
+		t.Error("response for `GET /users/1` should have been evicted, but still exists")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("expected cache length to be 2, but got: %d", cache.Len())
+	}
+}
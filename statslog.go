@@ -0,0 +1,57 @@
+package lrucache
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// statsLogger periodically writes a stats line for WithStatsLogger
+type statsLogger struct {
+	w        io.Writer
+	interval time.Duration
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+func (l *statsLogger) start(snapshot func() StatsSnapshot) {
+	l.stop = make(chan struct{})
+	l.done = make(chan struct{})
+	go func() {
+		defer close(l.done)
+		ticker := time.NewTicker(l.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-l.stop:
+				return
+			case <-ticker.C:
+				s := snapshot()
+				fmt.Fprintf(l.w, "lrucache: hits=%d misses=%d evictions=%d insertions=%d updates=%d len=%d capacity=%d\n",
+					s.Hits, s.Misses, s.Evictions, s.Insertions, s.Updates, s.Length, s.Capacity)
+			}
+		}
+	}()
+}
+
+// close stops the goroutine and waits for it to exit, so that nothing is
+// written after it returns
+func (l *statsLogger) close() {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+	<-l.done
+}
+
+// Close stops the background work started by options such as
+// WithStatsLogger. The cache stays usable afterwards. Close is safe to call
+// more than once and always returns nil.
+func (c *cache[K, V]) Close() error {
+	if c.statsLog != nil {
+		c.statsLog.close()
+	}
+	return nil
+}
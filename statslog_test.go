@@ -0,0 +1,34 @@
+package lrucache
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsLogger(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	cache, _ := New[int, int](2, WithStatsLogger[int, int](&buf, time.Millisecond))
+	cache.Put(1, 1)
+	cache.Get(1)
+	time.Sleep(20 * time.Millisecond)
+
+	if err := cache.Close(); err != nil {
+		t.Fatalf("expected Close to return nil, but got: %v", err)
+	}
+	// Close waits for the goroutine, so the buffer is safe to read and must
+	// not grow any more
+	logged := buf.String()
+	if !strings.Contains(logged, "lrucache: hits=1 misses=0 evictions=0 insertions=1 updates=0 len=1 capacity=2\n") {
+		t.Errorf("expected at least one stats line, but got: %q", logged)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if buf.String() != logged {
+		t.Error("expected nothing to be written after Close")
+	}
+	if err := cache.Close(); err != nil {
+		t.Errorf("expected a second Close to return nil, but got: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package lrucache
+
+// tagIndex maps every tag set by PutWithTags to the keys carrying it, and
+// every tagged key back to its tags, so that both directions can be kept in
+// sync as entries are removed
+type tagIndex[K comparable] struct {
+	keys map[string]map[K]struct{}
+	tags map[K][]string
+}
+
+func newTagIndex[K comparable]() *tagIndex[K] {
+	return &tagIndex[K]{
+		keys: make(map[string]map[K]struct{}),
+		tags: make(map[K][]string),
+	}
+}
+
+// set replaces the tags of key
+func (t *tagIndex[K]) set(key K, tags []string) {
+	t.remove(key)
+	if len(tags) == 0 {
+		return
+	}
+	t.tags[key] = append([]string(nil), tags...)
+	for _, tag := range tags {
+		keys, ok := t.keys[tag]
+		if !ok {
+			keys = make(map[K]struct{})
+			t.keys[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+// remove drops key from the index, along with tags no other key carries
+func (t *tagIndex[K]) remove(key K) {
+	tags, ok := t.tags[key]
+	if !ok {
+		return
+	}
+	delete(t.tags, key)
+	for _, tag := range tags {
+		keys := t.keys[tag]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(t.keys, tag)
+		}
+	}
+}
+
+func (t *tagIndex[K]) rename(oldKey, newKey K) {
+	if tags, ok := t.tags[oldKey]; ok {
+		t.remove(oldKey)
+		t.set(newKey, tags)
+	}
+}
+
+func (t *tagIndex[K]) clear() {
+	clear(t.keys)
+	clear(t.tags)
+}
+
+// PutWithTags stores the value like Put and associates it with tags,
+// replacing any tags the key had, so that it can be removed together with
+// other entries carrying one of them by DeleteByTag. A plain Put of a cached
+// key keeps its tags.
+func (c *cache[K, V]) PutWithTags(key K, value V, tags ...string) {
+	c.lockWritable()
+	defer c.unlock()
+
+	c.put(key, value, nil)
+	if _, ok := c.m[key]; !ok {
+		// rejected by WithMaxKeySize
+		return
+	}
+	if c.tags == nil {
+		c.tags = newTagIndex[K]()
+	}
+	c.tags.set(key, tags)
+}
+
+// DeleteByTag removes every entry carrying tag, e.g. to invalidate everything
+// tagged "user:42", and returns how many were removed.
+func (c *cache[K, V]) DeleteByTag(tag string) int {
+	c.lockWritable()
+	defer c.lock.Unlock()
+
+	if c.tags == nil {
+		return 0
+	}
+	deleted := 0
+	// remove updates the index, which is safe while ranging over it
+	for key := range c.tags.keys[tag] {
+		c.remove(c.m[key])
+		deleted++
+	}
+	return deleted
+}
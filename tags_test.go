@@ -0,0 +1,92 @@
+package lrucache
+
+import (
+	"slices"
+	"testing"
+)
+
+// checkTagIndex fails the test unless every indexed key is cached and the two
+// directions of the index agree
+func checkTagIndex[K comparable, V any](t *testing.T, c *cache[K, V]) {
+	t.Helper()
+	for key, tags := range c.tags.tags {
+		if _, ok := c.m[key]; !ok {
+			t.Errorf("expected tagged key %v to be cached", key)
+		}
+		for _, tag := range tags {
+			if _, ok := c.tags.keys[tag][key]; !ok {
+				t.Errorf("expected tag %s to list key %v", tag, key)
+			}
+		}
+	}
+	for tag, keys := range c.tags.keys {
+		if len(keys) == 0 {
+			t.Errorf("expected tag %s without keys to be dropped", tag)
+		}
+		for key := range keys {
+			if !slices.Contains(c.tags.tags[key], tag) {
+				t.Errorf("expected key %v to carry tag %s", key, tag)
+			}
+		}
+	}
+}
+
+func TestDeleteByTag(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[string, int](10)
+	cache.PutWithTags("profile", 1, "user:42")
+	cache.PutWithTags("settings", 2, "user:42", "settings")
+	cache.PutWithTags("other", 3, "user:7", "settings")
+	cache.Put("untagged", 4)
+
+	if n := cache.DeleteByTag("user:42"); n != 2 {
+		t.Errorf("expected 2 entries to be deleted, but got: %d", n)
+	}
+	if cache.Contains("profile") || cache.Contains("settings") {
+		t.Error("expected the entries tagged user:42 to be deleted")
+	}
+	if !cache.Contains("other") || !cache.Contains("untagged") {
+		t.Error("expected the other entries to be kept")
+	}
+	if n := cache.DeleteByTag("user:42"); n != 0 {
+		t.Errorf("expected no entries left to delete, but got: %d", n)
+	}
+	checkTagIndex(t, cache)
+
+	// retagging replaces the tags, a plain Put keeps them
+	cache.PutWithTags("other", 5, "user:8")
+	cache.Put("other", 6)
+	if n := cache.DeleteByTag("settings"); n != 0 {
+		t.Errorf("expected the replaced tag to match nothing, but got: %d", n)
+	}
+	if n := cache.DeleteByTag("user:8"); n != 1 {
+		t.Errorf("expected 1 entry to be deleted, but got: %d", n)
+	}
+	checkTagIndex(t, cache)
+}
+
+func TestTagIndexFollowsRemovals(t *testing.T) {
+	t.Parallel()
+	cache, _ := New[int, int](3)
+	for key := range 3 {
+		cache.PutWithTags(key, key, "all")
+	}
+	// evicts key 0
+	cache.Put(3, 3)
+	cache.Delete(1)
+	cache.Rename(2, 20)
+	checkTagIndex(t, cache)
+
+	if n := cache.DeleteByTag("all"); n != 1 {
+		t.Errorf("expected only the renamed entry to be deleted, but got: %d", n)
+	}
+	if cache.Contains(20) || !cache.Contains(3) {
+		t.Error("expected the renamed entry to keep its tag")
+	}
+
+	cache.PutWithTags(4, 4, "all")
+	cache.Clear()
+	if len(cache.tags.keys) != 0 || len(cache.tags.tags) != 0 {
+		t.Errorf("expected Clear to empty the tag index, but got: %v", cache.tags.keys)
+	}
+}
@@ -0,0 +1,79 @@
+package lrucache
+
+// Tiered combines a small, fast L1 cache in front of a larger, possibly
+// slower L2 cache. Lookups missing L1 fall back to L2, and L2 hits are
+// promoted into L1.
+type Tiered[K comparable, V any] struct {
+	l1, l2 Cache[K, V]
+	// set by WithL2Writes
+	l2Writes bool
+}
+
+// TieredOption configures a Tiered cache.
+type TieredOption[K comparable, V any] func(*Tiered[K, V])
+
+// WithL2Writes makes Put write to L2 only, leaving L1 to be filled by the Get
+// calls that hit L2. By default Put writes to both tiers.
+func WithL2Writes[K comparable, V any]() TieredOption[K, V] {
+	return func(t *Tiered[K, V]) {
+		t.l2Writes = true
+	}
+}
+
+// NewTiered returns a two-tier cache reading from l1 first, then from l2. To
+// demote entries evicted from l1 into l2, create l1 with
+// WithOnEvict(DemoteTo(l2)).
+func NewTiered[K comparable, V any](l1, l2 Cache[K, V], opts ...TieredOption[K, V]) *Tiered[K, V] {
+	t := &Tiered[K, V]{l1: l1, l2: l2}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Get looks up key in L1, then in L2, putting L2 hits into L1.
+func (t *Tiered[K, V]) Get(key K) (V, bool) {
+	if value, ok := t.l1.Get(key); ok {
+		return value, true
+	}
+	value, ok := t.l2.Get(key)
+	if ok {
+		t.l1.Put(key, value)
+	}
+	return value, ok
+}
+
+// Put stores the value in both tiers, or only in L2 with WithL2Writes.
+func (t *Tiered[K, V]) Put(key K, value V) {
+	if t.l2Writes {
+		// a stale copy must not keep shadowing the new value
+		t.l1.Delete(key)
+	} else {
+		t.l1.Put(key, value)
+	}
+	t.l2.Put(key, value)
+}
+
+// Delete removes key from both tiers.
+func (t *Tiered[K, V]) Delete(key K) {
+	t.l1.Delete(key)
+	t.l2.Delete(key)
+}
+
+// Len returns the length of L2, which holds every entry unless L1 is filled
+// independently of it. Entries only in L1 are not counted, so that Len never
+// adds up keys cached in both tiers.
+func (t *Tiered[K, V]) Len() int {
+	return t.l2.Len()
+}
+
+// DemoteTo returns a WithOnEvict callback that puts the entries evicted from
+// a cache into next, e.g. the L2 of a Tiered cache. Entries removed by Purge
+// are dropped rather than demoted.
+func DemoteTo[K comparable, V any](next Cache[K, V]) func(K, V, EvictionReason) {
+	return func(key K, value V, reason EvictionReason) {
+		if reason != ReasonCleared {
+			next.Put(key, value)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package lrucache
+
+import "testing"
+
+func TestTieredPromotesL2Hits(t *testing.T) {
+	t.Parallel()
+	l1, _ := New[string, int](2)
+	l2, _ := New[string, int](10)
+	tiered := NewTiered[string, int](l1, l2)
+
+	l2.Put("key", 1)
+	if val, ok := tiered.Get("key"); !ok || val != 1 {
+		t.Errorf("expected an L2 hit with value 1, but got: %d, %t", val, ok)
+	}
+	if val, ok := l1.Peek("key"); !ok || val != 1 {
+		t.Errorf("expected the L2 hit to be promoted into L1, but got: %d, %t", val, ok)
+	}
+	if _, ok := tiered.Get("absent"); ok {
+		t.Error("expected a miss in both tiers")
+	}
+
+	tiered.Put("both", 2)
+	if !l1.Contains("both") || !l2.Contains("both") {
+		t.Error("expected Put to write to both tiers")
+	}
+	tiered.Delete("both")
+	if l1.Contains("both") || l2.Contains("both") {
+		t.Error("expected Delete to remove the key from both tiers")
+	}
+}
+
+func TestTieredLen(t *testing.T) {
+	t.Parallel()
+	l1, _ := New[string, int](2)
+	l2, _ := New[string, int](10)
+	var tiered Cache[string, int] = NewTiered[string, int](l1, l2)
+
+	tiered.Put("a", 1)
+	tiered.Put("b", 2)
+	tiered.Put("c", 3)
+	if tiered.Len() != 3 {
+		t.Errorf("expected Len to be the L2 length 3, but got: %d", tiered.Len())
+	}
+	l1.Put("only-l1", 4)
+	if tiered.Len() != 3 {
+		t.Errorf("expected entries only in L1 not to be counted, but got: %d", tiered.Len())
+	}
+}
+
+func TestTieredL2Writes(t *testing.T) {
+	t.Parallel()
+	l1, _ := New[string, int](2)
+	l2, _ := New[string, int](10)
+	tiered := NewTiered(l1, l2, WithL2Writes[string, int]())
+
+	tiered.Put("key", 1)
+	if l1.Contains("key") || !l2.Contains("key") {
+		t.Error("expected Put to write to L2 only")
+	}
+	tiered.Get("key")
+	tiered.Put("key", 2)
+	if val, ok := tiered.Get("key"); !ok || val != 2 {
+		t.Errorf("expected the new value 2 to replace the promoted copy, but got: %d, %t", val, ok)
+	}
+}
+
+func TestTieredDemotion(t *testing.T) {
+	t.Parallel()
+	l2, _ := New[string, int](10)
+	l1, _ := New(2, WithOnEvict(DemoteTo[string, int](l2)))
+	tiered := NewTiered[string, int](l1, l2, WithL2Writes[string, int]())
+
+	l1.Put("a", 1)
+	l1.Put("b", 2)
+	l1.Put("c", 3)
+	if val, ok := l2.Peek("a"); !ok || val != 1 {
+		t.Errorf("expected the key evicted from L1 to be demoted into L2, but got: %d, %t", val, ok)
+	}
+	if val, ok := tiered.Get("a"); !ok || val != 1 {
+		t.Errorf("expected the demoted key to be served from L2, but got: %d, %t", val, ok)
+	}
+
+	l2.Delete("b")
+	l1.Purge()
+	if l2.Contains("b") {
+		t.Error("expected entries removed by Purge not to be demoted")
+	}
+}
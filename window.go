@@ -0,0 +1,41 @@
+package lrucache
+
+import "sync/atomic"
+
+// hitWindow records the outcomes of the most recent lookups in a ring that is
+// written without locks. A reader may see a slot claimed but not yet stored,
+// which only makes the ratio approximate.
+type hitWindow struct {
+	slots []atomic.Bool
+	next  atomic.Uint64
+}
+
+func newHitWindow(size int) *hitWindow {
+	return &hitWindow{slots: make([]atomic.Bool, size)}
+}
+
+func (w *hitWindow) record(hit bool) {
+	i := w.next.Add(1) - 1
+	w.slots[i%uint64(len(w.slots))].Store(hit)
+}
+
+// ratio returns the share of hits among the recorded lookups, or 0 if there
+// are none
+func (w *hitWindow) ratio() float64 {
+	filled := min(w.next.Load(), uint64(len(w.slots)))
+	if filled == 0 {
+		return 0
+	}
+	hits := 0
+	for i := range filled {
+		if w.slots[i].Load() {
+			hits++
+		}
+	}
+	return float64(hits) / float64(filled)
+}
+
+// reset must not run concurrently with record
+func (w *hitWindow) reset() {
+	w.next.Store(0)
+}